@@ -0,0 +1,166 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"matchmaker/libs/config"
+	"matchmaker/libs/solver"
+	"matchmaker/libs/types"
+	"matchmaker/libs/util"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	historyCmd.AddCommand(historyImportCmd)
+	rootCmd.AddCommand(historyCmd)
+}
+
+// getRepeatPolicy loads the pairing history and the configured policy for
+// recent pairs
+func getRepeatPolicy() (solver.RepeatPolicy, error) {
+	policy, err := config.GetRepeatPolicy()
+	if err != nil {
+		return solver.RepeatPolicy{}, err
+	}
+
+	history, err := types.LoadPairingHistory(config.GetHistoryFile())
+	if err != nil {
+		return solver.RepeatPolicy{}, fmt.Errorf("can't load pairing history: %w", err)
+	}
+	util.LogInfo("Pairing history loaded", map[string]interface{}{
+		"file":        config.GetHistoryFile(),
+		"pairings":    len(history.Pairings),
+		"recentWeeks": config.GetRecentWeeks(),
+		"policy":      policy,
+	})
+
+	return solver.RepeatPolicy{
+		History: history,
+		Weeks:   config.GetRecentWeeks(),
+		Forbid:  policy == config.RepeatPolicyForbid,
+	}, nil
+}
+
+// updatePairingHistory loads the pairing history, applies the update and
+// saves it back
+func updatePairingHistory(update func(history *types.PairingHistory)) error {
+	historyFile := config.GetHistoryFile()
+	history, err := types.LoadPairingHistory(historyFile)
+	if err != nil {
+		return err
+	}
+	update(history)
+	return history.Save(historyFile)
+}
+
+// importBatch records the pairings of the events of a batch file. Events
+// created before attendees were tracked in batches are skipped.
+func importBatch(history *types.PairingHistory, path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	var batch types.EventBatch
+	if err := json.Unmarshal(data, &batch); err != nil {
+		return 0, err
+	}
+
+	imported := 0
+	for _, event := range batch.Events {
+		if len(event.Attendees) < 2 {
+			logrus.Warnf("Skipping event %s of batch %s: attendees are unknown", event.ID, batch.ID)
+			continue
+		}
+		if history.Add(event.Attendees, event.StartTime, batch.ID, filepath.Base(path)) {
+			imported++
+		}
+	}
+	return imported, nil
+}
+
+// importPlanning records the pairings of the sessions of a planning file
+func importPlanning(history *types.PairingHistory, path string) (int, error) {
+	yml, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	solution, err := LoadPlan(yml)
+	if err != nil {
+		return 0, err
+	}
+
+	imported := 0
+	for _, session := range solution.Sessions {
+		if history.AddSession(session, "", filepath.Base(path)) {
+			imported++
+		}
+	}
+	return imported, nil
+}
+
+// batchFiles lists the batch files created by the plan command
+func batchFiles() []string {
+	entries, err := os.ReadDir("batches")
+	if err != nil {
+		return []string{}
+	}
+
+	files := []string{}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), "batch-") && strings.HasSuffix(entry.Name(), ".json") {
+			files = append(files, filepath.Join("batches", entry.Name()))
+		}
+	}
+	return files
+}
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Manage the history of past pairings.",
+	Long: `Manage the history of past pairings. The plan command records every session it creates in the
+history, and match and weekly-match use it to avoid pairing the same people again within the recent weeks.`,
+}
+
+var historyImportCmd = &cobra.Command{
+	Use:   "import [file...]",
+	Short: "Import past pairings from planning or batch files.",
+	Long: `Import past pairings into the history. Each file is either a planning file (planning.yml,
+weekly-planning.yml) or a batch file created by the plan command (.json).
+Without arguments, all batch files of the batches directory are imported.
+Pairings already in the history are not imported twice.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		files := args
+		if len(files) == 0 {
+			files = batchFiles()
+		}
+
+		historyFile := config.GetHistoryFile()
+		history, err := types.LoadPairingHistory(historyFile)
+		util.PanicOnError(err, "Can't load pairing history")
+
+		total := 0
+		for _, file := range files {
+			var imported int
+			if strings.HasSuffix(file, ".json") {
+				imported, err = importBatch(history, file)
+			} else {
+				imported, err = importPlanning(history, file)
+			}
+			util.PanicOnError(err, fmt.Sprintf("Can't import pairings from %s", file))
+
+			logrus.Infof("Imported %d pairings from %s", imported, file)
+			total += imported
+		}
+
+		util.PanicOnError(history.Save(historyFile), "Can't save pairing history")
+		fmt.Printf("✅ Imported %d pairings from %d files into %s (%d pairings in total)\n",
+			total, len(files), historyFile, len(history.Pairings))
+	},
+}
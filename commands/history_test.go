@@ -0,0 +1,171 @@
+package commands
+
+import (
+	"encoding/json"
+	"matchmaker/libs/config"
+	"matchmaker/libs/types"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// writeFile writes the data in a file of a temporary directory and returns its path
+func writeFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("can't write %s: %v", path, err)
+	}
+	return path
+}
+
+// checkPairing fails if the pairing doesn't record the people on the date
+func checkPairing(t *testing.T, pairing types.Pairing, people []string, date time.Time, batchID string, source string) {
+	t.Helper()
+	if !slices.Equal(pairing.People, people) {
+		t.Errorf("pairing people = %v, want %v", pairing.People, people)
+	}
+	if !pairing.Date.Equal(date) {
+		t.Errorf("pairing date = %v, want %v", pairing.Date, date)
+	}
+	if pairing.BatchID != batchID {
+		t.Errorf("pairing batch = %q, want %q", pairing.BatchID, batchID)
+	}
+	if pairing.Source != source {
+		t.Errorf("pairing source = %q, want %q", pairing.Source, source)
+	}
+}
+
+func TestImportBatch(t *testing.T) {
+	monday := time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)
+	tuesday := monday.AddDate(0, 0, 1)
+	batch := types.EventBatch{
+		ID: "batch-1",
+		Events: []types.Event{
+			{ID: "event-1", StartTime: monday, EndTime: monday.Add(time.Hour), Attendees: []string{"john@example.com", "alice@example.com"}},
+			{ID: "event-2", StartTime: tuesday, EndTime: tuesday.Add(time.Hour), Attendees: []string{"bob@example.com", "carol@example.com", "alice@example.com"}},
+			// Created before attendees were tracked
+			{ID: "event-3", StartTime: tuesday, EndTime: tuesday.Add(time.Hour)},
+		},
+	}
+	data, err := json.Marshal(batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := writeFile(t, "batch-1.json", data)
+
+	history, err := types.LoadPairingHistory(filepath.Join(t.TempDir(), "pairings.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	imported, err := importBatch(history, path)
+	if err != nil {
+		t.Fatalf("importBatch() error = %v", err)
+	}
+	if imported != 2 || len(history.Pairings) != 2 {
+		t.Fatalf("importBatch() imported %d pairings, history has %d, want 2", imported, len(history.Pairings))
+	}
+	checkPairing(t, history.Pairings[0], []string{"alice@example.com", "john@example.com"}, monday, "batch-1", "batch-1.json")
+	checkPairing(t, history.Pairings[1], []string{"alice@example.com", "bob@example.com", "carol@example.com"}, tuesday, "batch-1", "batch-1.json")
+
+	// Importing the same batch again doesn't count its pairings twice
+	imported, err = importBatch(history, path)
+	if err != nil {
+		t.Fatalf("importBatch() error = %v", err)
+	}
+	if imported != 0 || len(history.Pairings) != 2 {
+		t.Errorf("importBatch() again imported %d pairings, history has %d, want 0 and 2", imported, len(history.Pairings))
+	}
+
+	if _, err := importBatch(history, filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("importBatch() of a missing file succeeded, want an error")
+	}
+}
+
+// newPlanning returns a planning with a session for each squad, one day apart
+func newPlanning(start time.Time, squads ...[]*types.Person) *types.Solution {
+	solution := &types.Solution{}
+	for i, people := range squads {
+		sessionStart := start.AddDate(0, 0, i)
+		solution.Sessions = append(solution.Sessions, &types.ReviewSession{
+			Reviewers: &types.Squad{People: people},
+			Range:     &types.Range{Start: sessionStart, End: sessionStart.Add(time.Hour)},
+		})
+	}
+	return solution
+}
+
+func TestImportPlanning(t *testing.T) {
+	john := &types.Person{Email: "john@example.com"}
+	alice := &types.Person{Email: "alice@example.com"}
+	bob := &types.Person{Email: "bob@example.com"}
+	monday := time.Date(2024, 3, 4, 14, 0, 0, 0, time.UTC)
+
+	yml, err := yaml.Marshal(newPlanning(monday, []*types.Person{john, alice}, []*types.Person{bob, john}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := writeFile(t, "planning.yml", yml)
+
+	history := &types.PairingHistory{}
+	imported, err := importPlanning(history, path)
+	if err != nil {
+		t.Fatalf("importPlanning() error = %v", err)
+	}
+	if imported != 2 || len(history.Pairings) != 2 {
+		t.Fatalf("importPlanning() imported %d pairings, history has %d, want 2", imported, len(history.Pairings))
+	}
+	checkPairing(t, history.Pairings[0], []string{"alice@example.com", "john@example.com"}, monday, "", "planning.yml")
+	checkPairing(t, history.Pairings[1], []string{"bob@example.com", "john@example.com"}, monday.AddDate(0, 0, 1), "", "planning.yml")
+
+	imported, err = importPlanning(history, path)
+	if err != nil {
+		t.Fatalf("importPlanning() error = %v", err)
+	}
+	if imported != 0 || len(history.Pairings) != 2 {
+		t.Errorf("importPlanning() again imported %d pairings, history has %d, want 0 and 2", imported, len(history.Pairings))
+	}
+}
+
+func TestUpdatePairingHistory(t *testing.T) {
+	historyFile := filepath.Join(t.TempDir(), "history", "pairings.json")
+	viper.Set(config.HistoryFile, historyFile)
+	defer viper.Set(config.HistoryFile, nil)
+
+	john := &types.Person{Email: "john@example.com"}
+	alice := &types.Person{Email: "alice@example.com"}
+	monday := time.Date(2024, 3, 4, 14, 0, 0, 0, time.UTC)
+	first := newPlanning(monday, []*types.Person{john, alice})
+	second := newPlanning(monday.AddDate(0, 0, 7), []*types.Person{alice, john})
+
+	// Record the sessions the way the plan command does
+	record := func(solution *types.Solution, batchID string) {
+		t.Helper()
+		err := updatePairingHistory(func(history *types.PairingHistory) {
+			for _, session := range solution.Sessions {
+				history.AddSession(session, batchID, "planning.yml")
+			}
+		})
+		if err != nil {
+			t.Fatalf("updatePairingHistory() error = %v", err)
+		}
+	}
+	record(first, "batch-1")
+	record(second, "batch-2")
+	record(first, "batch-1")
+
+	history, err := types.LoadPairingHistory(historyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history.Pairings) != 2 {
+		t.Fatalf("history has %d pairings, want 2", len(history.Pairings))
+	}
+	checkPairing(t, history.Pairings[0], []string{"alice@example.com", "john@example.com"}, monday, "batch-1", "planning.yml")
+	checkPairing(t, history.Pairings[1], []string{"alice@example.com", "john@example.com"}, monday.AddDate(0, 0, 7), "batch-2", "planning.yml")
+}
@@ -1,15 +1,19 @@
 package commands
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"matchmaker/libs/config"
 	"matchmaker/libs/solver"
 	"matchmaker/libs/types"
 	"matchmaker/libs/util"
 	"os"
+	"os/signal"
 	"runtime/pprof"
 	"sort"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -18,19 +22,159 @@ import (
 
 var cpuprofile string
 
+// algorithm is the name of the solver strategy used to build the planning
+var algorithm string
+
+// timeLimit stops the search and keeps the best planning found when positive
+var timeLimit time.Duration
+
+// squadSize is the number of people attending each session, overriding the
+// configuration when set
+var squadSize int
+
+// Search parameters, overriding the configuration when set. Each accepts a
+// positive number or "auto" to pick a value from the size of the problem.
+var (
+	maxWidth       string
+	maxDepth       string
+	coveragePeriod string
+)
+
 func init() {
 	matchCmd.Flags().StringVarP(&cpuprofile, "cpuprofile", "c", "", `Target file to write cpu profile output.`)
+	matchCmd.Flags().StringVarP(&algorithm, "algorithm", "a", solver.DefaultAlgorithm,
+		fmt.Sprintf("Solver strategy used to build the planning (%s).", strings.Join(solver.Algorithms(), "|")))
+	matchCmd.Flags().DurationVarP(&timeLimit, "time-limit", "t", 0, `Stop the search after this duration (e.g. 30s, 2m) and
+keep the best planning found so far. Default value (0) searches until completion.`)
+	matchCmd.Flags().StringVar(&maxWidth, "max-width", "", `Number of alternatives explored at each step of the
+beam search, or "auto". Overrides the solver.maxWidthExploration configuration.`)
+	matchCmd.Flags().StringVar(&maxDepth, "max-depth", "", `Depth after which the beam search stops exploring
+alternatives, or "auto". Overrides the solver.maxExplorationPathLength configuration.`)
+	matchCmd.Flags().StringVar(&coveragePeriod, "coverage-period", "", `Granularity in minutes at which coverage is
+measured, or "auto". Overrides the solver.coveragePeriodMinutes configuration.`)
+
+	matchCmd.Flags().IntVar(&squadSize, "squad-size", 0, `Number of people attending each session, e.g. 3 for trios
+or 4 for mob reviews. Overrides the sessions.squadSize configuration.`)
+	addSeedFlag(matchCmd)
 
 	rootCmd.AddCommand(matchCmd)
 }
 
-func printMatchSummary(solution *solver.Solution) {
+// getSearchParameters reads the search parameters from the configuration,
+// overridden by the command flags when set
+func getSearchParameters() (solver.SearchParameters, error) {
+	parameters := solver.SearchParameters{}
+
+	width, err := config.GetMaxWidthExploration()
+	if maxWidth != "" {
+		width, err = config.ParseAutoInt(maxWidth)
+	}
+	if err != nil {
+		return parameters, fmt.Errorf("invalid max width: %w", err)
+	}
+	parameters.MaxWidthExploration = width
+
+	depth, err := config.GetMaxExplorationPathLength()
+	if maxDepth != "" {
+		depth, err = config.ParseAutoInt(maxDepth)
+	}
+	if err != nil {
+		return parameters, fmt.Errorf("invalid max depth: %w", err)
+	}
+	parameters.MaxExplorationPathLength = depth
+
+	period, err := config.GetCoveragePeriod()
+	if coveragePeriod != "" {
+		var minutes int
+		minutes, err = config.ParseAutoInt(coveragePeriod)
+		period = time.Duration(minutes) * time.Minute
+	}
+	if err != nil {
+		return parameters, fmt.Errorf("invalid coverage period: %w", err)
+	}
+	parameters.CoveragePeriodSpan = period
+
+	return parameters, nil
+}
+
+// getObjectiveWeights reads the weights of the planning score from the configuration
+func getObjectiveWeights() (solver.ObjectiveWeights, error) {
+	weights := solver.ObjectiveWeights{}
+	var err error
+
+	if weights.Coverage, err = config.GetCoverageWeight(); err != nil {
+		return weights, err
+	}
+	if weights.Fairness, err = config.GetFairnessWeight(); err != nil {
+		return weights, err
+	}
+	if weights.ReviewerLoad, err = config.GetReviewerLoadWeight(); err != nil {
+		return weights, err
+	}
+	if weights.Repetition, err = config.GetRepetitionWeight(); err != nil {
+		return weights, err
+	}
+	if weights.Team, err = config.GetTeamWeight(); err != nil {
+		return weights, err
+	}
+	return weights, nil
+}
+
+// getTeamPolicy reads how people of the same team are paired from the configuration
+func getTeamPolicy() (solver.TeamPolicy, error) {
+	policy, scope, err := config.GetTeamPolicy()
+	if err != nil {
+		return solver.TeamPolicy{}, err
+	}
+	return solver.TeamPolicy{
+		Mode:         policy,
+		ByDepartment: scope == config.TeamScopeDepartment,
+	}, nil
+}
+
+// printProgress renders the progress of the search on a single terminal line
+func printProgress(progress solver.Progress) {
+	fmt.Fprintf(os.Stderr, "\r⏳ %s | iterations: %d | best score: %.2f | depth: %d   ",
+		progress.Elapsed.Round(time.Second), progress.Iterations, progress.BestScore, progress.Depth)
+}
+
+func printMatchSummary(solution *solver.Solution, stats *solver.Stats) {
 	// Print summary message to standard output
 	fmt.Printf("\n✅ Planning file generated successfully!\n")
 	fmt.Printf("📅 Week: %s to %s\n",
 		solution.Sessions[0].Range.Start.Format("2006-01-02"),
 		solution.Sessions[len(solution.Sessions)-1].Range.End.Format("2006-01-02"))
 	fmt.Printf("👥 Sessions: %d\n", len(solution.Sessions))
+	fmt.Printf("🧮 Algorithm: %s (%d iterations in %s)\n",
+		stats.Algorithm, stats.Iterations, stats.Duration.Round(time.Millisecond))
+	fmt.Printf("🎲 Seed: %d\n", solution.Seed)
+	fmt.Printf("🔧 Parameters: max width %d, max depth %d, coverage period %s\n",
+		stats.Parameters.MaxWidthExploration, stats.Parameters.MaxExplorationPathLength, stats.Parameters.CoveragePeriodSpan)
+	fmt.Printf("📉 Missing coverage: %d (worst case: %d)\n", stats.MissingCoverage, stats.WorstMissingCoverage)
+	fmt.Printf("⚖️  Fairness (variance of sessions per person): %.2f\n", stats.Objective.Fairness)
+	fmt.Printf("🎓 Good reviewer load: %.2f\n", stats.Objective.ReviewerLoad)
+	fmt.Printf("🪜 Seniority: %s\n", stats.Seniority)
+	if matching, err := config.GetSkillMatching(); err == nil {
+		fmt.Printf("🧠 Skill matching: %s (expert proficiency: %d)\n", matching.Mode, matching.ExpertProficiency)
+	}
+	fmt.Printf("🔁 Repeated pairs: %.2f\n", stats.Objective.Repetition)
+	if policy, scope, err := config.GetTeamPolicy(); err == nil {
+		fmt.Printf("🏢 Team policy: %s by %s (same-team pairs: %.2f)\n", policy, scope, stats.Objective.Team)
+	}
+	fmt.Printf("📏 Constraints: %s (soft violations: %.2f)\n", stats.Constraints, stats.Objective.Constraints)
+	dailyLimit := "none"
+	if limit := config.GetMaxSessionsPerPersonPerDay(); limit > 0 {
+		dailyLimit = fmt.Sprintf("%d", limit)
+	}
+	fmt.Printf("📆 Max sessions per person per day: %s (busiest day: %d)\n", dailyLimit, stats.BusiestDay)
+	fmt.Printf("🎯 Score: %.2f (weights: coverage %g, fairness %g, reviewer load %g, repetition %g, team %g)\n",
+		stats.Objective.Score, stats.Weights.Coverage, stats.Weights.Fairness, stats.Weights.ReviewerLoad, stats.Weights.Repetition, stats.Weights.Team)
+	if stats.Optimal {
+		fmt.Printf("🏆 Proven optimal: no planning has a better score\n")
+	}
+	if stats.Interrupted {
+		fmt.Printf("⏱️  Search stopped early: this is the best planning found in time\n")
+	}
 
 	// Group sessions by day
 	sessionsByDay := make(map[string][]*types.ReviewSession)
@@ -97,13 +241,65 @@ file with reviewers tuples and planned slots.`,
 		util.PanicOnError(err, "Can't read problem description")
 		problem, err := types.LoadProblem(yml)
 		util.PanicOnError(err, "Can't load problem")
-		solution := solver.Solve(problem)
+		// the first Ctrl-C stops the search and keeps the best planning found
+		// so far, a second one kills the process
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			stop()
+		}()
+
+		parameters, err := getSearchParameters()
+		util.PanicOnError(err, "Invalid search parameters")
+		weights, err := getObjectiveWeights()
+		util.PanicOnError(err, "Invalid objective weights")
+		repeats, err := getRepeatPolicy()
+		util.PanicOnError(err, "Invalid pairing history configuration")
+		teams, err := getTeamPolicy()
+		util.PanicOnError(err, "Invalid team policy configuration")
+		size, err := config.GetSquadSize()
+		if cmd.Flags().Changed("squad-size") {
+			size, err = config.ValidateSquadSize(squadSize)
+		}
+		util.PanicOnError(err, "Invalid squad size")
+		_, err = config.GetSkillMatching()
+		util.PanicOnError(err, "Invalid skill matching configuration")
+		settings, err := config.GetConstraints()
+		util.PanicOnError(err, "Invalid constraints configuration")
+		constraints, err := solver.NewConstraints(settings)
+		util.PanicOnError(err, "Invalid constraints configuration")
+		seniority, err := config.GetSeniority()
+		util.PanicOnError(err, "Invalid seniority configuration")
+
+		solution, stats, err := solver.Solve(ctx, problem, solver.Options{
+			Algorithm:   algorithm,
+			TimeLimit:   timeLimit,
+			Progress:    printProgress,
+			Parameters:  parameters,
+			Seed:        getSeed(cmd),
+			Weights:     weights,
+			Repeats:     repeats,
+			Teams:       teams,
+			SquadSize:   size,
+			Constraints: constraints,
+			Seniority: solver.SeniorityRules{
+				Seniority: types.Seniority{
+					Levels:            seniority.Levels,
+					GoodReviewerLevel: seniority.GoodReviewerLevel,
+				},
+				MinLevel:    seniority.MinLevel,
+				MaxLevelGap: seniority.MaxLevelGap,
+			},
+		})
+		fmt.Fprintln(os.Stderr)
+		util.PanicOnError(err, "Can't solve problem")
 
 		planYml, err := yaml.Marshal(solution)
 		util.PanicOnError(err, "Can't marshal solution")
 		writeErr := os.WriteFile("./planning.yml", planYml, os.FileMode(0644))
 		util.PanicOnError(writeErr, "Can't write planning result")
 
-		printMatchSummary(solution)
+		printMatchSummary(solution, stats)
 	},
 }
@@ -3,6 +3,7 @@ package commands
 import (
 	"encoding/json"
 	"fmt"
+	"matchmaker/libs/config"
 	"matchmaker/libs/gcalendar"
 	"matchmaker/libs/types"
 	"matchmaker/libs/util"
@@ -14,7 +15,6 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
-	"google.golang.org/api/calendar/v3"
 	"gopkg.in/yaml.v3"
 )
 
@@ -102,7 +102,7 @@ If no file is specified, the command will:
 		yml, err := os.ReadFile(planningFile)
 		util.PanicOnError(err, "Can't read planning file")
 
-		cal, err := gcalendar.GetCalendarService()
+		cal, err := gcalendar.NewGCalendar()
 		util.PanicOnError(err, "Can't get gcalendar client")
 
 		solution, err := LoadPlan(yml)
@@ -115,56 +115,14 @@ If no file is specified, the command will:
 			Events:    make([]types.Event, 0),
 		}
 
-		// calendar owner
+		// calendar owner, attending every session as optional
 		masterEmail := viper.GetString("organizerEmail")
 
 		for _, session := range solution.Sessions {
-			attendees := []*calendar.EventAttendee{}
+			attendeeEmails := session.Reviewers.Emails()
+			organizer := gcalendar.SessionOrganizer(session, masterEmail)
 
-			for _, person := range session.Reviewers.People {
-				attendees = append(attendees, &calendar.EventAttendee{
-					Email: person.Email,
-				})
-			}
-
-			// take first attendee as organizer
-			organizer := attendees[0].Email
-
-			// add master email as optional, and use it as organizer by default
-			if masterEmail != "" {
-				organizer = masterEmail
-				attendees = append(attendees, &calendar.EventAttendee{
-					Email:    masterEmail,
-					Optional: true,
-				})
-			}
-
-			event := &calendar.Event{
-				Start: &calendar.EventDateTime{
-					DateTime: gcalendar.FormatTime(session.Range.Start),
-					TimeZone: viper.GetString("workingHours.timezone"),
-				},
-				End: &calendar.EventDateTime{
-					DateTime: gcalendar.FormatTime(session.Range.End),
-					TimeZone: viper.GetString("workingHours.timezone"),
-				},
-				Summary:         session.GetEventSummary(),
-				Attendees:       attendees,
-				GuestsCanModify: true,
-				ConferenceData: &calendar.ConferenceData{
-					CreateRequest: &calendar.CreateConferenceRequest{
-						RequestId: uuid.New().String(),
-						ConferenceSolutionKey: &calendar.ConferenceSolutionKey{
-							Type: "hangoutsMeet",
-						},
-						Status: &calendar.ConferenceRequestStatus{
-							StatusCode: "success",
-						},
-					},
-				},
-			}
-
-			createdEvent, err := cal.Events.Insert(organizer, event).ConferenceDataVersion(1).Do()
+			createdEvent, err := cal.CreateSessionEvent(session, masterEmail)
 			util.PanicOnError(err, "Can't create event")
 			logrus.Info("✔ " + session.GetDisplayName())
 
@@ -175,6 +133,7 @@ If no file is specified, the command will:
 				Organizer: organizer,
 				StartTime: session.Range.Start,
 				EndTime:   session.Range.End,
+				Attendees: attendeeEmails,
 			})
 		}
 
@@ -194,5 +153,17 @@ If no file is specified, the command will:
 				}
 			}
 		}
+
+		// Record the pairings so that next matches avoid repeating them
+		err = updatePairingHistory(func(history *types.PairingHistory) {
+			for _, session := range solution.Sessions {
+				history.AddSession(session, batch.ID, filepath.Base(planningFile))
+			}
+		})
+		if err != nil {
+			logrus.Warnf("Failed to update pairing history: %v", err)
+		} else {
+			logrus.Infof("Recorded %d pairings in %s", len(solution.Sessions), config.GetHistoryFile())
+		}
 	},
 }
@@ -27,12 +27,23 @@ func loadProblem(weekShift int, groupFile string) *types.Problem {
 	util.PanicOnError(err, "Cannot connect to Google Calendar")
 	util.LogInfo("Connected to Google Calendar", nil)
 
+	// Availability is computed in the time zone and working hours of each
+	// person, the work ranges cover the working hours of everyone
+	err = util.ResolveWorkingHours(people)
+	util.PanicOnError(err, "Invalid working hours configuration")
+
+	participants := make([]*types.Person, 0, len(people))
+	for _, person := range people {
+		if person.CanParticipateInSession() {
+			participants = append(participants, person)
+		}
+	}
+
 	beginOfWeek := util.FirstDayOfISOWeek(weekShift)
-	workRangesChan, err := util.GetWeekWorkRanges(beginOfWeek)
+	workRanges, err := util.GetPeopleWorkRanges(beginOfWeek, participants)
 	if err != nil {
 		panic(fmt.Errorf("failed to get work ranges: %w", err))
 	}
-	workRanges := util.ToSlice(workRangesChan)
 
 	// Log work ranges in a human-readable format
 	util.LogInfo("Work ranges for the week", map[string]interface{}{
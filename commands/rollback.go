@@ -176,6 +176,7 @@ func rollbackBatch(batch *types.EventBatch, batchID string) {
 	printRollbackSummary(len(batch.Events), successfulDeletions, failedDeletions)
 
 	if failedDeletions == 0 {
+		removePairings(batchID)
 		handleBatchFileDeletion(batchID)
 	} else {
 		logrus.Warn("Batch file was not deleted due to failed event deletions")
@@ -202,6 +203,19 @@ func deleteEvents(cal *calendar.Service, events []types.Event) (int, int) {
 	return successfulDeletions, failedDeletions
 }
 
+// removePairings removes the pairings of a rolled back batch from the pairing history
+func removePairings(batchID string) {
+	removed := 0
+	err := updatePairingHistory(func(history *types.PairingHistory) {
+		removed = history.RemoveBatch(batchID)
+	})
+	if err != nil {
+		logrus.Warnf("Failed to update pairing history: %v", err)
+	} else {
+		logrus.Infof("Removed %d pairings from the pairing history", removed)
+	}
+}
+
 // printRollbackSummary displays the results of the rollback operation
 func printRollbackSummary(totalEvents, successfulDeletions, failedDeletions int) {
 	logrus.Infof("Rollback summary:")
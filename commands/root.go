@@ -3,6 +3,7 @@ package commands
 import (
 	"matchmaker/libs/util"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -15,6 +16,25 @@ time together by putting common appointments in their Google Calendar when
 they are both not busy.`,
 }
 
+// seed is the random seed used by the matching commands, shared by their
+// --seed flag
+var seed int64
+
+// addSeedFlag registers the --seed flag on a matching command
+func addSeedFlag(cmd *cobra.Command) {
+	cmd.Flags().Int64Var(&seed, "seed", 0, `Random seed used to shuffle people and slots. Reusing the seed
+written in a planning file regenerates the same planning. Default is a new seed on each run.`)
+}
+
+// getSeed returns the seed given with --seed, or a new one based on the
+// current time when the flag is not set
+func getSeed(cmd *cobra.Command) int64 {
+	if cmd.Flags().Changed("seed") {
+		return seed
+	}
+	return time.Now().UnixNano()
+}
+
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		util.LogError(err, "Failed to execute command")
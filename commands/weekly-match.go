@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"matchmaker/libs/config"
 	"matchmaker/libs/gcalendar"
 	"matchmaker/libs/solver"
 	"matchmaker/libs/types"
@@ -8,21 +9,39 @@ import (
 	"math/rand"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"time"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 )
 
+// rotation pairs people by the round robin rotation instead of randomly,
+// overriding the rotation.enabled configuration
+var rotation bool
+
 func init() {
+	addSeedFlag(weeklyMatchCmd)
+	weeklyMatchCmd.Flags().IntVarP(&weekShift, "week-shift", "w", 0, `define a week shift to schedule an upcoming
+week instead of next week. Default value (0) is next week, and 1 is the week after, etc.`)
+	weeklyMatchCmd.Flags().BoolVar(&rotation, "rotation", false, `Pair people by a round robin rotation, so that everyone meets everyone
+once before any repeat. Overrides the rotation.enabled configuration.`)
+
 	rootCmd.AddCommand(weeklyMatchCmd)
 }
 
 var weeklyMatchCmd = &cobra.Command{
 	Use:   "weekly-match [group-file]",
-	Short: "Create random pairs of people with no common skills and schedule sessions across weeks.",
-	Long: `Create random pairs of people from a group file, ensuring that paired people have no common skills.
-Then schedule pairing sessions for each tuple across consecutive weeks. The output is a 'weekly-planning.yml' file.`,
+	Short: "Create random pairs of people with no common skills and schedule their sessions in the target week.",
+	Long: `Create random pairs of people from a group file, ensuring that paired people have no common skills,
+or matching them according to the skills.matching configuration. With --rotation, pairs follow
+a round robin rotation saved between runs, so that everyone meets everyone once before any repeat.
+Then schedule pairing sessions for each tuple in the target week, or spread across the weeks of the
+weeklyMatch.cadenceWeeks configuration. Pairs left without a session are recovered in the following
+weeks, re-paired with each other or swapped with scheduled pairs, unless weeklyMatch.recovery is false. With the trio policy of
+weeklyMatch.leftovers, people left unpaired join a scheduled pair as a trio. The output is a
+'weekly-planning.yml' file.`,
 	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		groupFile := "group.yml"
@@ -30,15 +49,52 @@ Then schedule pairing sessions for each tuple across consecutive weeks. The outp
 			groupFile = args[0]
 		}
 
+		seed := getSeed(cmd)
+		r := rand.New(rand.NewSource(seed))
+
 		util.LogInfo("Starting weekly match process", map[string]interface{}{
 			"groupFile": groupFile,
+			"seed":      seed,
 		})
 
 		// Load and filter available people
-		availablePeople := loadAndFilterPeople(groupFile)
+		people, availablePeople := loadAndFilterPeople(groupFile)
 
-		// Create random pairs
-		tuples := createRandomPairs(availablePeople)
+		cadence, err := config.GetWeeklyMatchCadence()
+		util.PanicOnError(err, "Invalid weekly match configuration")
+		beginOfWeek := util.FirstDayOfISOWeek(weekShift)
+
+		// Create random pairs, avoiding people paired recently
+		repeats, err := getRepeatPolicy()
+		util.PanicOnError(err, "Invalid pairing history configuration")
+		matching, err := config.GetSkillMatching()
+		util.PanicOnError(err, "Invalid skill matching configuration")
+		teams, err := getTeamPolicy()
+		util.PanicOnError(err, "Invalid team policy configuration")
+		leftovers, err := config.GetLeftoverPolicy()
+		util.PanicOnError(err, "Invalid weekly match configuration")
+		recoveryWeeks, err := config.GetWeeklyMatchRecoveryWeeks()
+		util.PanicOnError(err, "Invalid weekly match configuration")
+		rules := pairingRules{
+			repeats:        repeats,
+			matching:       matching,
+			teams:          teams,
+			firstWeekShift: weekShift,
+			lastWeekShift:  weekShift + cadence - 1,
+			available:      availablePeople,
+		}
+		// A rotation plays one round per run, whose pairs are spread over the
+		// cadence weeks like random pairs
+		var tuples types.Tuples
+		var roundRobin *types.Rotation
+		rotationFile := config.GetRotationFile(groupFile)
+		if rotation || (!cmd.Flags().Changed("rotation") && config.IsRotationEnabled()) {
+			roundRobin, err = types.LoadRotation(rotationFile)
+			util.PanicOnError(err, "Can't load rotation")
+			tuples, roundRobin = createRotationPairs(people, availablePeople, roundRobin, r, beginOfWeek)
+		} else {
+			tuples = createRandomPairs(availablePeople, r, rules)
+		}
 
 		// Get Google Calendar service
 		cal, err := gcalendar.NewGCalendar()
@@ -46,14 +102,37 @@ Then schedule pairing sessions for each tuple across consecutive weeks. The outp
 		util.LogInfo("Connected to Google Calendar", nil)
 
 		// Process tuples and create sessions
-		combinedSolution, allUnmatchedTuples, allUnmatchedPeople := processTuplesAndCreateSessions(tuples, cal)
+		schedule := newWeeklySchedule(cal, r)
+		allUnmatchedTuples, unscheduledPeople := processTuplesAndCreateSessions(tuples, schedule, weekShift, cadence)
+
+		// Recover the pairs left without a session
+		if config.IsWeeklyMatchRecoveryEnabled() && len(allUnmatchedTuples) > 0 {
+			allUnmatchedTuples, unscheduledPeople = recoverUnmatchedTuples(tuples, allUnmatchedTuples, schedule, rules, weekShift, cadence, recoveryWeeks)
+		}
+
+		// Add the people left unpaired to a pair as a trio
+		if leftovers == config.LeftoverPolicyTrio {
+			tuples.UnpairedPeople = foldIntoTrios(tuples.UnpairedPeople, schedule, rules)
+		}
+		allUnmatchedPeople := append(slices.Clone(tuples.UnpairedPeople), unscheduledPeople...)
+		schedule.solution.Seed = seed
 
 		// Output results
-		outputResults(combinedSolution, tuples, allUnmatchedTuples, allUnmatchedPeople)
+		outputResults(schedule.solution, tuples, allUnmatchedTuples, allUnmatchedPeople)
+
+		// Only a round whose sessions are scheduled counts as played
+		if roundRobin != nil {
+			util.PanicOnError(roundRobin.Save(rotationFile), "Can't save rotation")
+			util.LogInfo("Rotation saved", map[string]interface{}{
+				"file": rotationFile,
+			})
+		}
 	},
 }
 
-func loadAndFilterPeople(groupFile string) []*types.Person {
+// loadAndFilterPeople returns the people of the group file, and the ones
+// available for sessions this week
+func loadAndFilterPeople(groupFile string) ([]*types.Person, []*types.Person) {
 	groupPath := filepath.Join("groups", groupFile)
 	people, err := types.LoadPersons(groupPath)
 	util.PanicOnError(err, "Cannot load people file")
@@ -62,6 +141,10 @@ func loadAndFilterPeople(groupFile string) []*types.Person {
 		"file":  groupPath,
 	})
 
+	// Availability is computed in the time zone and working hours of each person
+	err = util.ResolveWorkingHours(people)
+	util.PanicOnError(err, "Invalid working hours configuration")
+
 	// Filter out people with maxSessionsPerWeek = 0
 	availablePeople := make([]*types.Person, 0)
 	for _, person := range people {
@@ -74,58 +157,85 @@ func loadAndFilterPeople(groupFile string) []*types.Person {
 		"availablePeople": len(availablePeople),
 	})
 
-	return availablePeople
+	return people, availablePeople
 }
 
-func createRandomPairs(availablePeople []*types.Person) types.Tuples {
+// missedPartnerPenalty ranks pairs leaving out a required partner of one of
+// the people after any other pair
+const missedPartnerPenalty = 10
+
+// createRandomPairs pairs as many people as possible according to the skill
+// matching: people with no common skills by default, an expert with a
+// learner of the same skill for transfer matching. People paired recently are
+// left out when the repeat policy forbids it, as are people of teams excluded
+// by the team policy. People are never paired with someone they avoid, and
+// required partners are paired together first: people with required partners
+// are paired with someone else only when none of them is available. Among the
+// pairings of the other people leaving the fewest of them unpaired, pairs are
+// picked in a random order, people not paired recently first, from other
+// teams when the team policy prefers it, and learning the most from each
+// other with complementary matching.
+func createRandomPairs(availablePeople []*types.Person, r *rand.Rand, rules pairingRules) types.Tuples {
 	tuples := types.Tuples{
 		Pairs:          make([]types.Tuple, 0),
 		UnpairedPeople: make([]*types.Person, 0),
 	}
 
-	// Create a local random generator
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-
 	// Shuffle the people array
 	util.LogInfo("Shuffling people for random pairing", nil)
 	r.Shuffle(len(availablePeople), func(i, j int) {
 		availablePeople[i], availablePeople[j] = availablePeople[j], availablePeople[i]
 	})
 
-	// Create pairs with no common skills
-	used := make(map[*types.Person]bool)
+	// Build the graph of the pairs allowed, apart from the required ones
+	required, edges := []solver.MatchingEdge{}, []solver.MatchingEdge{}
 	for i, person1 := range availablePeople {
-		if used[person1] {
-			continue
-		}
-
-		// Find a person with no common skills
 		for j := i + 1; j < len(availablePeople); j++ {
-			person2 := availablePeople[j]
-			if used[person2] {
+			penalty, ok := rules.matchPenalty(person1, availablePeople[j])
+			if !ok {
 				continue
 			}
-
-			// Check if they have no common skills
-			commonSkills := util.Intersection(person1.Skills, person2.Skills)
-			if len(commonSkills) == 0 {
-				tuples.Pairs = append(tuples.Pairs, types.Tuple{
-					Person1: person1,
-					Person2: person2,
-				})
-				used[person1] = true
-				used[person2] = true
-				util.LogInfo("Created pair", map[string]interface{}{
-					"person1": person1.Email,
-					"person2": person2.Email,
-				})
-				break
+			edge := solver.MatchingEdge{A: i, B: j, Penalty: penalty}
+			if types.PairingRequired([]*types.Person{person1, availablePeople[j]}) {
+				required = append(required, edge)
+			} else {
+				edges = append(edges, edge)
 			}
 		}
-		if !used[person1] {
-			tuples.UnpairedPeople = append(tuples.UnpairedPeople, person1)
+	}
+
+	// Pair as many required partners as possible, then as many of the other
+	// people as possible
+	pairs := solver.MaximumMatching(len(availablePeople), required, r)
+	paired := make(map[int]bool)
+	for _, pair := range pairs {
+		paired[pair[0]], paired[pair[1]] = true, true
+	}
+	edges = slices.DeleteFunc(edges, func(edge solver.MatchingEdge) bool {
+		return paired[edge.A] || paired[edge.B]
+	})
+	pairs = append(pairs, solver.MaximumMatching(len(availablePeople), edges, r)...)
+
+	used := make(map[*types.Person]bool)
+	for _, pair := range pairs {
+		person1, partner := availablePeople[pair[0]], availablePeople[pair[1]]
+		tuples.Pairs = append(tuples.Pairs, types.Tuple{
+			Person1: person1,
+			Person2: partner,
+		})
+		used[person1] = true
+		used[partner] = true
+		util.LogInfo("Created pair", map[string]interface{}{
+			"person1": person1.Email,
+			"person2": partner.Email,
+		})
+	}
+
+	for _, person := range availablePeople {
+		if !used[person] {
+			tuples.UnpairedPeople = append(tuples.UnpairedPeople, person)
 			util.LogInfo("Person could not be paired", map[string]interface{}{
-				"email": person1.Email,
+				"email": person.Email,
 			})
 		}
 	}
@@ -133,17 +243,277 @@ func createRandomPairs(availablePeople []*types.Person) types.Tuples {
 	return tuples
 }
 
-func processTuplesAndCreateSessions(tuples types.Tuples, cal *gcalendar.GCalendar) (*types.Solution, []types.Tuple, []*types.Person) {
-	combinedSolution := &types.Solution{
-		Sessions: make([]*types.ReviewSession, 0),
+// createRotationPairs pairs the available people by a round robin rotation
+// over the group, playing its next round, or the round already played this
+// week. A new rotation in a random order starts without a saved one. The
+// circle of the rotation only changes when a new cycle starts, adding the
+// newcomers in a random order: until then, newcomers and the partners of
+// people not available this week sit out the round, as do pairs of people
+// avoiding each other. It returns the rotation with the round recorded, to be
+// saved once the sessions are scheduled.
+func createRotationPairs(group []*types.Person, availablePeople []*types.Person, schedule *types.Rotation, r *rand.Rand, week time.Time) (types.Tuples, *types.Rotation) {
+	tuples := types.Tuples{
+		Pairs:          make([]types.Tuple, 0),
+		UnpairedPeople: make([]*types.Person, 0),
+	}
+
+	peopleByEmail := map[string]*types.Person{}
+	for _, person := range availablePeople {
+		peopleByEmail[person.Email] = person
+	}
+	emails := make([]string, 0, len(group))
+	for _, person := range group {
+		emails = append(emails, person.Email)
+	}
+	r.Shuffle(len(emails), func(i, j int) {
+		emails[i], emails[j] = emails[j], emails[i]
+	})
+
+	if schedule == nil {
+		schedule = types.NewRotation(emails)
+		util.LogInfo("Starting a new rotation", map[string]interface{}{
+			"people": len(emails),
+			"rounds": schedule.Rounds(),
+		})
+	} else if schedule.Renew(emails, week) {
+		util.LogInfo("Starting a new rotation cycle with the people of the group", map[string]interface{}{
+			"people": len(emails),
+			"rounds": schedule.Rounds(),
+		})
+	}
+
+	round := schedule.RoundOf(week)
+	util.LogInfo("Playing rotation round", map[string]interface{}{
+		"round":  round + 1,
+		"rounds": schedule.Rounds(),
+		"week":   week.Format("2006-01-02"),
+	})
+
+	paired := map[*types.Person]bool{}
+	for _, pair := range schedule.Pairs(round) {
+		person1, person2 := peopleByEmail[pair[0]], peopleByEmail[pair[1]]
+		if person1 == nil || person2 == nil {
+			util.LogInfo("Rotation pair not available this week, skipping", map[string]interface{}{
+				"person1": pair[0],
+				"person2": pair[1],
+			})
+			continue
+		}
+		if person1.Avoids(person2) {
+			util.LogInfo("Rotation pair avoids each other, skipping", map[string]interface{}{
+				"person1": person1.Email,
+				"person2": person2.Email,
+			})
+			continue
+		}
+		tuples.Pairs = append(tuples.Pairs, types.Tuple{Person1: person1, Person2: person2})
+		paired[person1], paired[person2] = true, true
+		util.LogInfo("Created pair", map[string]interface{}{
+			"person1": person1.Email,
+			"person2": person2.Email,
+		})
+	}
+	for _, person := range availablePeople {
+		if !paired[person] {
+			tuples.UnpairedPeople = append(tuples.UnpairedPeople, person)
+			util.LogInfo("Person could not be paired", map[string]interface{}{
+				"email":      person.Email,
+				"inRotation": schedule.Contains(person.Email),
+			})
+		}
+	}
+
+	return tuples, schedule
+}
+
+// pairingRules decide which people a weekly match can pair
+type pairingRules struct {
+	repeats  solver.RepeatPolicy
+	matching config.SkillMatching
+	teams    solver.TeamPolicy
+
+	// firstWeekShift and lastWeekShift are the weeks of the match, pairs
+	// being spread over them
+	firstWeekShift int
+	lastWeekShift  int
+
+	// available are the people of the match, who required partners are
+	// looked for
+	available []*types.Person
+}
+
+// penalty returns false if the people can't be paired in the week of the week
+// shift, and otherwise how little the pair is wanted: -1 for required
+// partners, more for people paired recently before that week, of the same
+// team when the team policy prefers otherwise, far from complementary, or
+// leaving out a required partner
+func (p pairingRules) penalty(person1 *types.Person, person2 *types.Person, weekShift int) (float64, bool) {
+	people := []*types.Person{person1, person2}
+	if person1.Avoids(person2) {
+		return 0, false
+	}
+
+	// Required pairs skip the skills, repeats and teams checks
+	if types.PairingRequired(people) {
+		return -1, true
+	}
+
+	// Check if they match by skills
+	skillsPenalty, ok := weeklySkillsPenalty(p.matching, people)
+	if !ok {
+		return 0, false
+	}
+
+	reference := util.FirstDayOfISOWeek(weekShift)
+	if !p.repeats.Allows(people, reference) || !p.teams.Allows(people) {
+		return 0, false
+	}
+
+	penalty := p.repeats.Penalty(people, reference) + p.teams.Penalty(people) + skillsPenalty
+	if !types.PairingAllowed(people, p.available) {
+		penalty += missedPartnerPenalty
+	}
+	return penalty, true
+}
+
+// matchPenalty returns false unless the people can be paired in every week of
+// the match, and otherwise their highest penalty over these weeks. The week
+// of a new pair is only known once the pairs are ordered.
+func (p pairingRules) matchPenalty(person1 *types.Person, person2 *types.Person) (float64, bool) {
+	highest := 0.0
+	for weekShift := p.firstWeekShift; weekShift <= p.lastWeekShift; weekShift++ {
+		penalty, ok := p.penalty(person1, person2, weekShift)
+		if !ok {
+			return 0, false
+		}
+		if weekShift == p.firstWeekShift || penalty > highest {
+			highest = penalty
+		}
+	}
+	return highest, true
+}
+
+// lowestPenalty returns false if the people can't be paired in any week from
+// the first week shift to the last, and otherwise their lowest penalty over
+// the weeks allowing them
+func (p pairingRules) lowestPenalty(person1 *types.Person, person2 *types.Person, firstWeekShift int, lastWeekShift int) (float64, bool) {
+	lowest, found := 0.0, false
+	for weekShift := firstWeekShift; weekShift <= lastWeekShift; weekShift++ {
+		if penalty, ok := p.penalty(person1, person2, weekShift); ok && (!found || penalty < lowest) {
+			lowest, found = penalty, true
+		}
+	}
+	return lowest, found
+}
+
+// allows returns a function telling whether the pairing rules allow the
+// people of the tuple in the week of a week shift, see findInWeeks
+func (p pairingRules) allows(tuple types.Tuple) func(weekShift int) bool {
+	return func(weekShift int) bool {
+		_, ok := p.penalty(tuple.Person1, tuple.Person2, weekShift)
+		return ok
+	}
+}
+
+// weeklySkillsPenalty returns false if the people can't be paired by the
+// skill matching, and otherwise how far they are from complementary
+func weeklySkillsPenalty(matching config.SkillMatching, people []*types.Person) (float64, bool) {
+	switch matching.Mode {
+	case config.SkillMatchingOverlap:
+		// Weekly pairs are made of people with no common skills
+		for i, person := range people {
+			for _, other := range people[i+1:] {
+				if len(util.Intersection(person.Skills.Names(), other.Skills.Names())) > 0 {
+					return 0, false
+				}
+			}
+		}
+		return 0, true
+	case config.SkillMatchingTransfer:
+		return 0, solver.SkillsViolation(matching, people) == 0
+	default:
+		return solver.ComplementarityViolation(matching, people), true
+	}
+}
+
+// busyCalendar gives the busy times of people within work ranges, read from
+// their Google calendars by gcalendar.GCalendar
+type busyCalendar interface {
+	GetBusyTimesForPeople(people []*types.Person, workRanges []*types.Range) []*types.BusyTime
+}
+
+// weeklySchedule holds the sessions of a weekly match, with the week shift of
+// each session
+type weeklySchedule struct {
+	solution *types.Solution
+	weeks    map[*types.ReviewSession]int
+	cal      busyCalendar
+	r        *rand.Rand
+}
+
+func newWeeklySchedule(cal busyCalendar, r *rand.Rand) *weeklySchedule {
+	return &weeklySchedule{
+		solution: &types.Solution{Sessions: make([]*types.ReviewSession, 0)},
+		weeks:    make(map[*types.ReviewSession]int),
+		cal:      cal,
+		r:        r,
+	}
+}
+
+// find returns a session for the tuple in the week of the week shift, given
+// the sessions scheduled except the replaced ones, nil if there is none
+func (s *weeklySchedule) find(tuple types.Tuple, weekShift int, replaced ...*types.ReviewSession) *types.ReviewSession {
+	workRanges, err := util.GetPeopleWorkRanges(util.FirstDayOfISOWeek(weekShift), tuple.People())
+	util.PanicOnError(err, "Failed to get work ranges")
+	busyTimes := getBusyTimesForTuple(tuple, workRanges, s.cal)
+
+	planned := slices.DeleteFunc(slices.Clone(s.solution.Sessions), func(session *types.ReviewSession) bool {
+		return slices.Contains(replaced, session)
+	})
+	return solver.FindSessionForTuple(tuple, workRanges, busyTimes, planned, s.r)
+}
+
+// findInWeeks returns a session for the tuple in the first week from the first
+// week shift to the last allowing the tuple and having one, with its week
+// shift, nil if none has. Every week allows the tuple when allowed is nil.
+func (s *weeklySchedule) findInWeeks(tuple types.Tuple, firstWeekShift int, lastWeekShift int, allowed func(weekShift int) bool) (*types.ReviewSession, int) {
+	for weekShift := firstWeekShift; weekShift <= lastWeekShift; weekShift++ {
+		if allowed != nil && !allowed(weekShift) {
+			continue
+		}
+		if session := s.find(tuple, weekShift); session != nil {
+			return session, weekShift
+		}
 	}
+	return nil, 0
+}
+
+// add schedules a session in the week of the week shift
+func (s *weeklySchedule) add(session *types.ReviewSession, weekShift int) {
+	s.solution.Sessions = append(s.solution.Sessions, session)
+	s.weeks[session] = weekShift
+}
+
+// replace schedules a session instead of a scheduled one, in the same week
+func (s *weeklySchedule) replace(replaced *types.ReviewSession, session *types.ReviewSession) {
+	index := slices.Index(s.solution.Sessions, replaced)
+	s.solution.Sessions[index] = session
+	s.weeks[session] = s.weeks[replaced]
+	delete(s.weeks, replaced)
+}
+
+// processTuplesAndCreateSessions schedules a session for each pair, spreading
+// the pairs over the cadence weeks starting at the week shift. It returns the
+// pairs left without a session with their people.
+func processTuplesAndCreateSessions(tuples types.Tuples, schedule *weeklySchedule, firstWeekShift int, cadence int) ([]types.Tuple, []*types.Person) {
+	combinedSolution := schedule.solution
+	cal, r := schedule.cal, schedule.r
 
 	allUnmatchedTuples := make([]types.Tuple, 0)
 	allUnmatchedPeople := make([]*types.Person, 0)
-	allUnmatchedPeople = append(allUnmatchedPeople, tuples.UnpairedPeople...)
 
 	for i, tuple := range tuples.Pairs {
-		weekShift := i
+		weekShift := firstWeekShift + i%cadence
 		util.LogInfo("Processing tuple for week", map[string]interface{}{
 			"tupleIndex": i,
 			"weekShift":  weekShift,
@@ -152,14 +522,13 @@ func processTuplesAndCreateSessions(tuples types.Tuples, cal *gcalendar.GCalenda
 		})
 
 		beginOfWeek := util.FirstDayOfISOWeek(weekShift)
-		workRangesChan, err := util.GetWeekWorkRanges(beginOfWeek)
+		workRanges, err := util.GetPeopleWorkRanges(beginOfWeek, tuple.People())
 		util.PanicOnError(err, "Failed to get work ranges")
-		workRanges := util.ToSlice(workRangesChan)
 		busyTimes := getBusyTimesForTuple(tuple, workRanges, cal)
 
 		// Log problem details
 		util.LogInfo("Problem details", map[string]interface{}{
-			"people": []string{tuple.Person1.Email, tuple.Person2.Email},
+			"people": tuple.Emails(),
 			"workRanges": []string{
 				workRanges[0].Start.Format(time.RFC3339),
 				workRanges[0].End.Format(time.RFC3339),
@@ -177,10 +546,10 @@ func processTuplesAndCreateSessions(tuples types.Tuples, cal *gcalendar.GCalenda
 			})
 		}
 
-		session := solver.FindSessionForTuple(tuple, workRanges, busyTimes)
+		session := solver.FindSessionForTuple(tuple, workRanges, busyTimes, combinedSolution.Sessions, r)
 
 		if session != nil {
-			combinedSolution.Sessions = append(combinedSolution.Sessions, session)
+			schedule.add(session, weekShift)
 			util.LogInfo("Added session for tuple", map[string]interface{}{
 				"tupleIndex":   i,
 				"weekShift":    weekShift,
@@ -199,12 +568,77 @@ func processTuplesAndCreateSessions(tuples types.Tuples, cal *gcalendar.GCalenda
 		}
 	}
 
-	return combinedSolution, allUnmatchedTuples, allUnmatchedPeople
+	return allUnmatchedTuples, allUnmatchedPeople
+}
+
+// foldIntoTrios adds each leftover person to a scheduled pair as a trio, and
+// returns the people left unpaired. The pairing rules must allow the person
+// with each person of the pair, as for any new pair, and the trio must have a
+// common free slot in the week of the pair, which replaces the session of the
+// pair. Pairs are tried in a random order, the least penalized by the pairing
+// rules first.
+func foldIntoTrios(leftovers []*types.Person, schedule *weeklySchedule, rules pairingRules) []*types.Person {
+	unpaired := make([]*types.Person, 0)
+	for _, person := range leftovers {
+		candidates := make([]*types.ReviewSession, 0)
+		penalties := make(map[*types.ReviewSession]float64)
+		for _, session := range schedule.solution.Sessions {
+			pair := session.Reviewers.People
+			if len(pair) != 2 {
+				continue
+			}
+			penalty1, ok1 := rules.penalty(person, pair[0], schedule.weeks[session])
+			penalty2, ok2 := rules.penalty(person, pair[1], schedule.weeks[session])
+			if !ok1 || !ok2 {
+				continue
+			}
+			candidates = append(candidates, session)
+			penalties[session] = penalty1 + penalty2
+		}
+		schedule.r.Shuffle(len(candidates), func(i, j int) {
+			candidates[i], candidates[j] = candidates[j], candidates[i]
+		})
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return penalties[candidates[i]] < penalties[candidates[j]]
+		})
+
+		if !foldIntoTrio(person, candidates, schedule) {
+			unpaired = append(unpaired, person)
+			util.LogInfo("Person could not join a pair as a trio", map[string]interface{}{
+				"email": person.Email,
+			})
+		}
+	}
+	return unpaired
+}
+
+// foldIntoTrio replaces the session of the first candidate pair having a
+// common free slot with the person by a trio session, and returns false if
+// none has
+func foldIntoTrio(person *types.Person, candidates []*types.ReviewSession, schedule *weeklySchedule) bool {
+	for _, session := range candidates {
+		pair := session.Reviewers.People
+		trio := types.Tuple{Person1: pair[0], Person2: pair[1], Person3: person}
+		trioSession := schedule.find(trio, schedule.weeks[session], session)
+		if trioSession == nil {
+			continue
+		}
+
+		schedule.replace(session, trioSession)
+		util.LogInfo("Added person to a pair as a trio", map[string]interface{}{
+			"email":        person.Email,
+			"people":       trio.Emails(),
+			"weekShift":    schedule.weeks[trioSession],
+			"sessionStart": trioSession.Start().Format(time.RFC3339),
+			"sessionEnd":   trioSession.End().Format(time.RFC3339),
+		})
+		return true
+	}
+	return false
 }
 
-func getBusyTimesForTuple(tuple types.Tuple, workRanges []*types.Range, cal *gcalendar.GCalendar) []*types.BusyTime {
-	tuplePeople := []*types.Person{tuple.Person1, tuple.Person2}
-	return cal.GetBusyTimesForPeople(tuplePeople, workRanges)
+func getBusyTimesForTuple(tuple types.Tuple, workRanges []*types.Range, cal busyCalendar) []*types.BusyTime {
+	return cal.GetBusyTimesForPeople(tuple.People(), workRanges)
 }
 
 func outputResults(combinedSolution *types.Solution, tuples types.Tuples, allUnmatchedTuples []types.Tuple, allUnmatchedPeople []*types.Person) {
@@ -215,6 +649,7 @@ func outputResults(combinedSolution *types.Solution, tuples types.Tuples, allUnm
 		"totalSessions":   len(combinedSolution.Sessions),
 		"unmatchedTuples": len(allUnmatchedTuples),
 		"outputFile":      "./weekly-planning.yml",
+		"seed":            combinedSolution.Seed,
 	})
 
 	// Print all sessions
@@ -232,8 +667,7 @@ func outputResults(combinedSolution *types.Solution, tuples types.Tuples, allUnm
 		})
 		for _, tuple := range allUnmatchedTuples {
 			util.LogInfo("Unmatched tuple", map[string]interface{}{
-				"person1": tuple.Person1.Email,
-				"person2": tuple.Person2.Email,
+				"people": tuple.Emails(),
 			})
 		}
 	}
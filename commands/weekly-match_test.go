@@ -0,0 +1,557 @@
+package commands
+
+import (
+	"fmt"
+	"matchmaker/libs/config"
+	"matchmaker/libs/holidays"
+	"matchmaker/libs/solver"
+	"matchmaker/libs/testutils"
+	"matchmaker/libs/types"
+	"matchmaker/libs/util"
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+)
+
+// fakeCalendar gives the busy times of people without reading their
+// calendars. Like Google calendars, it returns new ranges on each call, as
+// merging busy ranges modifies them.
+type fakeCalendar struct {
+	// busy lists the busy ranges of people by email
+	busy map[string][]*types.Range
+}
+
+func (c *fakeCalendar) GetBusyTimesForPeople(people []*types.Person, workRanges []*types.Range) []*types.BusyTime {
+	busyTimes := []*types.BusyTime{}
+	for _, person := range people {
+		for _, busyRange := range c.busy[person.Email] {
+			busyTimes = append(busyTimes, &types.BusyTime{
+				Person: person,
+				Range:  &types.Range{Start: busyRange.Start, End: busyRange.End},
+			})
+		}
+	}
+	return busyTimes
+}
+
+// alwaysBusy is a busy range covering every week a test schedules
+var alwaysBusy = &types.Range{Start: time.Now().AddDate(-1, 0, 0), End: time.Now().AddDate(1, 0, 0)}
+
+// setupWeeklyMatch sets up the working hours and no holidays, and returns a
+// function restoring them
+func setupWeeklyMatch() func() {
+	configMock := testutils.NewConfigMock()
+	configMock.SetupWorkHours()
+	originalHolidayGetter := holidays.DefaultHolidayGetter
+	holidays.DefaultHolidayGetter = func(country holidays.Country, start, end time.Time) ([]holidays.Holiday, error) {
+		return []holidays.Holiday{}, nil
+	}
+	return func() {
+		holidays.DefaultHolidayGetter = originalHolidayGetter
+		configMock.Restore()
+	}
+}
+
+// newPeople returns people with the given email prefix
+func newPeople(prefix string, count int) []*types.Person {
+	people := make([]*types.Person, count)
+	for i := range people {
+		people[i] = &types.Person{Email: fmt.Sprintf("%s%d@example.com", prefix, i), MaxSessionsPerWeek: 2}
+	}
+	return people
+}
+
+// newTuples pairs the people in order
+func newTuples(people []*types.Person) types.Tuples {
+	tuples := types.Tuples{Pairs: []types.Tuple{}, UnpairedPeople: []*types.Person{}}
+	for i := 0; i+1 < len(people); i += 2 {
+		tuples.Pairs = append(tuples.Pairs, types.Tuple{Person1: people[i], Person2: people[i+1]})
+	}
+	return tuples
+}
+
+// inWeek returns true if the session starts in the week of the week shift
+func inWeek(session *types.ReviewSession, weekShift int) bool {
+	beginOfWeek := util.FirstDayOfISOWeek(weekShift)
+	return !session.Start().Before(beginOfWeek) && session.Start().Before(beginOfWeek.AddDate(0, 0, 7))
+}
+
+// pairedEmails returns the pairs of the tuples by email, each in both orders
+func pairedEmails(tuples types.Tuples) map[[2]string]bool {
+	pairs := map[[2]string]bool{}
+	for _, tuple := range tuples.Pairs {
+		pairs[[2]string{tuple.Person1.Email, tuple.Person2.Email}] = true
+		pairs[[2]string{tuple.Person2.Email, tuple.Person1.Email}] = true
+	}
+	return pairs
+}
+
+func TestCreateRotationPairs(t *testing.T) {
+	a := &types.Person{Email: "a@example.com", MaxSessionsPerWeek: 1}
+	b := &types.Person{Email: "b@example.com", MaxSessionsPerWeek: 1}
+	c := &types.Person{Email: "c@example.com", MaxSessionsPerWeek: 1}
+	d := &types.Person{Email: "d@example.com", MaxSessionsPerWeek: 1}
+	newcomer := &types.Person{Email: "newcomer@example.com", MaxSessionsPerWeek: 1}
+	group := []*types.Person{a, b, c, d}
+	everyone := []*types.Person{a, b, c, d, newcomer}
+	week := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	r := rand.New(rand.NewSource(1))
+
+	// A new rotation pairs everyone
+	tuples, rotation := createRotationPairs(group, group, nil, r, week)
+	if len(tuples.Pairs) != 2 || len(tuples.UnpairedPeople) != 0 {
+		t.Fatalf("createRotationPairs() = %d pairs, %d unpaired, want 2 pairs", len(tuples.Pairs), len(tuples.UnpairedPeople))
+	}
+	circle := append([]string{}, rotation.People...)
+	met := pairedEmails(tuples)
+
+	// Someone not available this week sits out with their partner, and a
+	// newcomer waits for the next cycle, keeping the circle
+	secondWeek := week.AddDate(0, 0, 7)
+	available := []*types.Person{a, b, c, newcomer}
+	tuples, rotation = createRotationPairs(everyone, available, rotation, r, secondWeek)
+	if len(tuples.Pairs) != 1 || len(tuples.UnpairedPeople) != 2 {
+		t.Errorf("createRotationPairs() with an absent person = %d pairs, %d unpaired, want 1 pair and 2 unpaired", len(tuples.Pairs), len(tuples.UnpairedPeople))
+	}
+	for pair := range pairedEmails(tuples) {
+		if met[pair] {
+			t.Errorf("createRotationPairs() repeated the pair %v within the cycle", pair)
+		}
+		if pair[0] == d.Email || pair[1] == d.Email || pair[0] == newcomer.Email || pair[1] == newcomer.Email {
+			t.Errorf("createRotationPairs() paired %v, want the absent person and the newcomer left out", pair)
+		}
+	}
+	for i, email := range circle {
+		if rotation.People[i] != email {
+			t.Fatalf("createRotationPairs() changed the circle to %v within the cycle, want %v", rotation.People, circle)
+		}
+	}
+
+	// The newcomer joins when the next cycle starts, after the third round
+	_, rotation = createRotationPairs(everyone, group, rotation, r, week.AddDate(0, 0, 14))
+	if rotation.Contains(newcomer.Email) {
+		t.Error("createRotationPairs() added the newcomer during the last round of the cycle")
+	}
+	tuples, rotation = createRotationPairs(everyone, everyone, rotation, r, week.AddDate(0, 0, 21))
+	if !rotation.Contains(newcomer.Email) || len(tuples.Pairs) != 2 || len(tuples.UnpairedPeople) != 1 {
+		t.Errorf("createRotationPairs() at a new cycle = %v, %d pairs, %d unpaired, want the newcomer in the circle", rotation.People, len(tuples.Pairs), len(tuples.UnpairedPeople))
+	}
+}
+
+func TestProcessTuplesAndCreateSessions(t *testing.T) {
+	defer setupWeeklyMatch()()
+
+	tests := []struct {
+		name      string
+		pairs     int
+		busy      []int
+		weekShift int
+		cadence   int
+		weeks     []int
+	}{
+		{name: "target week", pairs: 3, cadence: 1, weeks: []int{0, 0, 0}},
+		{name: "week shift", pairs: 2, weekShift: 2, cadence: 1, weeks: []int{2, 2}},
+		{name: "two weeks cadence", pairs: 4, weekShift: 1, cadence: 2, weeks: []int{1, 2, 1, 2}},
+		{name: "cadence longer than the pairs", pairs: 2, cadence: 3, weeks: []int{0, 1}},
+		{name: "busy pair", pairs: 3, busy: []int{1}, cadence: 2, weeks: []int{0, -1, 0}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			people := newPeople("person", 2*test.pairs)
+			cal := &fakeCalendar{busy: map[string][]*types.Range{}}
+			for _, pair := range test.busy {
+				cal.busy[people[2*pair].Email] = []*types.Range{alwaysBusy}
+			}
+			tuples := newTuples(people)
+			schedule := newWeeklySchedule(cal, rand.New(rand.NewSource(1)))
+
+			unmatchedTuples, unmatchedPeople := processTuplesAndCreateSessions(tuples, schedule, test.weekShift, test.cadence)
+
+			if want := test.pairs - len(test.busy); len(schedule.solution.Sessions) != want {
+				t.Fatalf("processTuplesAndCreateSessions() scheduled %d sessions, want %d", len(schedule.solution.Sessions), want)
+			}
+			if len(unmatchedTuples) != len(test.busy) || len(unmatchedPeople) != 2*len(test.busy) {
+				t.Errorf("processTuplesAndCreateSessions() left %d tuples and %d people unmatched, want %d and %d", len(unmatchedTuples), len(unmatchedPeople), len(test.busy), 2*len(test.busy))
+			}
+			for _, tuple := range unmatchedTuples {
+				if tuple != tuples.Pairs[test.busy[0]] {
+					t.Errorf("processTuplesAndCreateSessions() left %v unmatched, want the busy pair", tuple.Emails())
+				}
+			}
+
+			// Each pair is scheduled in its week of the cadence
+			sessions := map[types.Tuple]*types.ReviewSession{}
+			for _, session := range schedule.solution.Sessions {
+				people := session.Reviewers.People
+				sessions[types.Tuple{Person1: people[0], Person2: people[1]}] = session
+			}
+			for i, tuple := range tuples.Pairs {
+				session, week := sessions[tuple], test.weeks[i]
+				if week < 0 {
+					continue
+				}
+				if session == nil {
+					t.Fatalf("processTuplesAndCreateSessions() scheduled no session for pair %d", i)
+				}
+				if schedule.weeks[session] != week || !inWeek(session, week) {
+					t.Errorf("processTuplesAndCreateSessions() scheduled pair %d at %v in week %d, want week %d", i, session.Start(), schedule.weeks[session], week)
+				}
+			}
+		})
+	}
+}
+
+// newRules returns pairing rules matching people with no common skills
+func newRules(available []*types.Person) pairingRules {
+	return pairingRules{
+		matching:  config.DefaultSkillMatching,
+		available: available,
+	}
+}
+
+// teamPolicy returns a function setting the team policy of pairing rules
+func teamPolicy(mode string, byDepartment bool) func(rules pairingRules) pairingRules {
+	return func(rules pairingRules) pairingRules {
+		rules.teams = solver.TeamPolicy{Mode: mode, ByDepartment: byDepartment}
+		return rules
+	}
+}
+
+func TestPairingRulesPenalty(t *testing.T) {
+	newcomer := &types.Person{Email: "newcomer@example.com", PairWith: []string{"buddy@example.com"}}
+	buddy := &types.Person{Email: "buddy@example.com"}
+	manager := &types.Person{Email: "manager@example.com", Avoid: []string{"report@example.com"}}
+	report := &types.Person{Email: "report@example.com"}
+	other := &types.Person{Email: "other@example.com"}
+	payments1 := &types.Person{Email: "payments1@example.com", Team: "payments", Department: "engineering"}
+	payments2 := &types.Person{Email: "payments2@example.com", Team: "payments", Department: "engineering"}
+	search := &types.Person{Email: "search@example.com", Team: "search", Department: "engineering"}
+	everyone := []*types.Person{newcomer, buddy, manager, report, other, payments1, payments2, search}
+
+	tests := []struct {
+		name      string
+		person1   *types.Person
+		person2   *types.Person
+		available []*types.Person
+		rules     func(rules pairingRules) pairingRules
+		penalty   float64
+		allowed   bool
+	}{
+		{name: "no rule", person1: report, person2: other, allowed: true},
+		{name: "avoided person", person1: manager, person2: report},
+		{name: "avoided by the other person", person1: report, person2: manager},
+		{name: "required partner", person1: newcomer, person2: buddy, penalty: -1, allowed: true},
+		{name: "required partner of the other person", person1: buddy, person2: newcomer, penalty: -1, allowed: true},
+		{name: "required partner left out", person1: newcomer, person2: other, penalty: missedPartnerPenalty, allowed: true},
+		{name: "required partner unavailable", person1: newcomer, person2: other, available: []*types.Person{newcomer, other}, allowed: true},
+		{
+			name:    "avoided required partner",
+			person1: newcomer,
+			person2: &types.Person{Email: "buddy@example.com", Avoid: []string{"newcomer@example.com"}},
+		},
+		{
+			name:    "required partner with a common skill",
+			person1: &types.Person{Email: "newcomer@example.com", PairWith: []string{"buddy@example.com"}, Skills: types.Skills{"go": 1}},
+			person2: &types.Person{Email: "buddy@example.com", Skills: types.Skills{"go": 3}},
+			penalty: -1,
+			allowed: true,
+		},
+		{name: "same team preferring cross-team pairs", person1: payments1, person2: payments2, rules: teamPolicy(config.TeamPolicyPreferCross, false), penalty: 1, allowed: true},
+		{name: "cross-team pair preferring cross-team pairs", person1: payments1, person2: search, rules: teamPolicy(config.TeamPolicyPreferCross, false), allowed: true},
+		{name: "unknown team preferring cross-team pairs", person1: payments1, person2: other, rules: teamPolicy(config.TeamPolicyPreferCross, false), allowed: true},
+		{name: "same team requiring cross-team pairs", person1: payments1, person2: payments2, rules: teamPolicy(config.TeamPolicyRequireCross, false)},
+		{name: "cross-team pair requiring cross-team pairs", person1: payments1, person2: search, rules: teamPolicy(config.TeamPolicyRequireCross, false), allowed: true},
+		{name: "same department requiring cross-department pairs", person1: payments1, person2: search, rules: teamPolicy(config.TeamPolicyRequireCross, true)},
+		{name: "same team requiring same-team pairs", person1: payments1, person2: payments2, rules: teamPolicy(config.TeamPolicySameTeam, false), allowed: true},
+		{name: "cross-team pair requiring same-team pairs", person1: payments1, person2: search, rules: teamPolicy(config.TeamPolicySameTeam, false)},
+		{name: "unknown team requiring same-team pairs", person1: payments1, person2: other, rules: teamPolicy(config.TeamPolicySameTeam, false), allowed: true},
+		{
+			name:    "required partner of the same team requiring cross-team pairs",
+			person1: &types.Person{Email: "newcomer@example.com", PairWith: []string{"buddy@example.com"}, Team: "payments"},
+			person2: &types.Person{Email: "buddy@example.com", Team: "payments"},
+			rules:   teamPolicy(config.TeamPolicyRequireCross, false),
+			penalty: -1,
+			allowed: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			available := test.available
+			if available == nil {
+				available = everyone
+			}
+			rules := newRules(available)
+			if test.rules != nil {
+				rules = test.rules(rules)
+			}
+
+			penalty, allowed := rules.penalty(test.person1, test.person2, 0)
+			if allowed != test.allowed || (allowed && penalty != test.penalty) {
+				t.Errorf("penalty() = %v, %v, want %v, %v", penalty, allowed, test.penalty, test.allowed)
+			}
+		})
+	}
+}
+
+// checkPairs fails the test if a pair of the tuples isn't allowed by the
+// rules, or if someone is paired twice or neither paired nor unpaired
+func checkPairs(t *testing.T, tuples types.Tuples, people []*types.Person, rules pairingRules) {
+	t.Helper()
+	seen := map[*types.Person]int{}
+	for _, tuple := range tuples.Pairs {
+		if _, ok := rules.penalty(tuple.Person1, tuple.Person2, 0); !ok {
+			t.Errorf("createRandomPairs() paired %v, which the rules forbid", tuple.Emails())
+		}
+		seen[tuple.Person1]++
+		seen[tuple.Person2]++
+	}
+	for _, person := range tuples.UnpairedPeople {
+		seen[person]++
+	}
+	for _, person := range people {
+		if seen[person] != 1 {
+			t.Errorf("createRandomPairs() placed %s %d times, want once", person.Email, seen[person])
+		}
+	}
+}
+
+func TestCreateRandomPairs(t *testing.T) {
+	newcomer := &types.Person{Email: "newcomer@example.com", PairWith: []string{"buddy@example.com"}}
+	buddy := &types.Person{Email: "buddy@example.com"}
+	manager := &types.Person{Email: "manager@example.com", Avoid: []string{"report@example.com"}}
+	report := &types.Person{Email: "report@example.com"}
+
+	for seed := int64(0); seed < 10; seed++ {
+		people := []*types.Person{newcomer, buddy, manager, report}
+		rules := newRules(slices.Clone(people))
+		tuples := createRandomPairs(slices.Clone(people), rand.New(rand.NewSource(seed)), rules)
+
+		// The newcomer is paired with their buddy, so the manager and their
+		// report can't be paired
+		checkPairs(t, tuples, people, rules)
+		if pairs := pairedEmails(tuples); len(tuples.Pairs) != 1 || !pairs[[2]string{newcomer.Email, buddy.Email}] {
+			t.Errorf("seed %d: createRandomPairs() = %v, want the newcomer with their buddy only", seed, pairs)
+		}
+
+		// A second newcomer of the same buddy is paired with someone else
+		second := &types.Person{Email: "second@example.com", PairWith: []string{buddy.Email}}
+		people = []*types.Person{newcomer, second, buddy, report}
+		rules = newRules(slices.Clone(people))
+		tuples = createRandomPairs(slices.Clone(people), rand.New(rand.NewSource(seed)), rules)
+		checkPairs(t, tuples, people, rules)
+		if pairs := pairedEmails(tuples); len(tuples.Pairs) != 2 || (!pairs[[2]string{newcomer.Email, buddy.Email}] && !pairs[[2]string{second.Email, buddy.Email}]) {
+			t.Errorf("seed %d: createRandomPairs() = %v, want a newcomer with the buddy and the other one with the report", seed, pairs)
+		}
+	}
+}
+
+func TestCreateRandomPairsTeams(t *testing.T) {
+	payments := newPeople("payments", 2)
+	search := newPeople("search", 2)
+	for _, person := range payments {
+		person.Team = "payments"
+	}
+	for _, person := range search {
+		person.Team = "search"
+	}
+	people := append(slices.Clone(payments), search...)
+
+	for _, mode := range []string{config.TeamPolicyRequireCross, config.TeamPolicyPreferCross, config.TeamPolicySameTeam} {
+		for seed := int64(0); seed < 10; seed++ {
+			rules := teamPolicy(mode, false)(newRules(slices.Clone(people)))
+			tuples := createRandomPairs(slices.Clone(people), rand.New(rand.NewSource(seed)), rules)
+
+			checkPairs(t, tuples, people, rules)
+			if len(tuples.Pairs) != 2 {
+				t.Fatalf("%s, seed %d: createRandomPairs() = %d pairs, want 2", mode, seed, len(tuples.Pairs))
+			}
+			for _, tuple := range tuples.Pairs {
+				if sameTeam := tuple.Person1.Team == tuple.Person2.Team; sameTeam != (mode == config.TeamPolicySameTeam) {
+					t.Errorf("%s, seed %d: createRandomPairs() paired %v", mode, seed, tuple.Emails())
+				}
+			}
+		}
+	}
+}
+
+// newChain returns people who can only be paired with the previous and the
+// next one, avoiding everyone else
+func newChain(length int) []*types.Person {
+	people := newPeople("chain", length)
+	for i, person := range people {
+		for j, other := range people {
+			if j < i-1 || j > i+1 {
+				person.Avoid = append(person.Avoid, other.Email)
+			}
+		}
+	}
+	return people
+}
+
+func TestCreateRandomPairsMatching(t *testing.T) {
+	tests := []struct {
+		name     string
+		people   []*types.Person
+		pairs    int
+		unpaired int
+	}{
+		{name: "even chain", people: newChain(4), pairs: 2},
+		{name: "long even chain", people: newChain(8), pairs: 4},
+		{name: "odd chain", people: newChain(5), pairs: 2, unpaired: 1},
+		{name: "long odd chain", people: newChain(9), pairs: 4, unpaired: 1},
+		{name: "nobody to pair", people: newPeople("alone", 1), unpaired: 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			unpaired := map[string]bool{}
+			for seed := int64(0); seed < 20; seed++ {
+				rules := newRules(slices.Clone(test.people))
+				tuples := createRandomPairs(slices.Clone(test.people), rand.New(rand.NewSource(seed)), rules)
+
+				checkPairs(t, tuples, test.people, rules)
+				if len(tuples.Pairs) != test.pairs || len(tuples.UnpairedPeople) != test.unpaired {
+					t.Errorf("seed %d: createRandomPairs() = %d pairs, %d unpaired, want %d and %d", seed, len(tuples.Pairs), len(tuples.UnpairedPeople), test.pairs, test.unpaired)
+				}
+				for _, person := range tuples.UnpairedPeople {
+					unpaired[person.Email] = true
+				}
+			}
+
+			// The person left out of an odd chain changes with the seed
+			if test.unpaired == 1 && len(test.people) > 1 && len(unpaired) < 2 {
+				t.Errorf("createRandomPairs() always left out %v, want a random person", unpaired)
+			}
+		})
+	}
+}
+
+func TestPairingRulesWeeks(t *testing.T) {
+	person1 := &types.Person{Email: "person1@example.com", MaxSessionsPerWeek: 2}
+	person2 := &types.Person{Email: "person2@example.com", MaxSessionsPerWeek: 2}
+
+	// The people are paired during the first week of the match, so the pair
+	// only repeats from the second week
+	history := &types.PairingHistory{}
+	history.Add([]string{person1.Email, person2.Email}, util.FirstDayOfISOWeek(0).AddDate(0, 0, 1), "", "")
+	rules := newRules([]*types.Person{person1, person2})
+	rules.lastWeekShift = 1
+	rules.repeats = solver.RepeatPolicy{History: history, Weeks: 4, Forbid: true}
+
+	if _, ok := rules.penalty(person1, person2, 0); !ok {
+		t.Error("penalty() in the first week forbids a pair made during that week")
+	}
+	if _, ok := rules.penalty(person1, person2, 1); ok {
+		t.Error("penalty() in the second week allows a pair made the week before")
+	}
+	if _, ok := rules.matchPenalty(person1, person2); ok {
+		t.Error("matchPenalty() allows a pair forbidden in a week of the match")
+	}
+	if penalty, ok := rules.lowestPenalty(person1, person2, 0, 1); !ok || penalty != 0 {
+		t.Errorf("lowestPenalty() = %v, %v, want the penalty of the first week", penalty, ok)
+	}
+	if allowed := rules.allows(types.Tuple{Person1: person1, Person2: person2}); !allowed(0) || allowed(1) {
+		t.Error("allows() doesn't follow the week of the pair")
+	}
+
+	// Penalized repeats count with their highest penalty over the match
+	rules.repeats.Forbid = false
+	if penalty, ok := rules.matchPenalty(person1, person2); !ok || penalty != 1 {
+		t.Errorf("matchPenalty() = %v, %v, want the penalty of the second week", penalty, ok)
+	}
+}
+
+// scheduleTuples schedules a session for each pair of the tuples in the target week
+func scheduleTuples(t *testing.T, tuples types.Tuples, cal *fakeCalendar) *weeklySchedule {
+	schedule := newWeeklySchedule(cal, rand.New(rand.NewSource(1)))
+	if unmatched, _ := processTuplesAndCreateSessions(tuples, schedule, 0, 1); len(unmatched) > 0 {
+		t.Fatalf("processTuplesAndCreateSessions() left %d pairs without a session", len(unmatched))
+	}
+	return schedule
+}
+
+func TestFoldIntoTrios(t *testing.T) {
+	defer setupWeeklyMatch()()
+
+	recentHistory := &types.PairingHistory{}
+	recentHistory.Add([]string{"leftover@example.com", "pair0@example.com"}, util.FirstDayOfISOWeek(-1), "", "")
+
+	tests := []struct {
+		name     string
+		leftover *types.Person
+		rules    func(rules pairingRules) pairingRules
+		busy     bool
+		folded   bool
+	}{
+		{
+			name:     "free slot",
+			leftover: &types.Person{Email: "leftover@example.com", MaxSessionsPerWeek: 2},
+			folded:   true,
+		},
+		{
+			name:     "avoided host",
+			leftover: &types.Person{Email: "leftover@example.com", MaxSessionsPerWeek: 2, Avoid: []string{"pair1@example.com"}},
+		},
+		{
+			name:     "common skill",
+			leftover: &types.Person{Email: "leftover@example.com", MaxSessionsPerWeek: 2, Skills: types.Skills{"go": 1}},
+		},
+		{
+			name:     "recent pair forbidden",
+			leftover: &types.Person{Email: "leftover@example.com", MaxSessionsPerWeek: 2},
+			rules: func(rules pairingRules) pairingRules {
+				rules.repeats = solver.RepeatPolicy{History: recentHistory, Weeks: 4, Forbid: true}
+				return rules
+			},
+		},
+		{
+			name:     "same team required across teams",
+			leftover: &types.Person{Email: "leftover@example.com", MaxSessionsPerWeek: 2, Team: "payments"},
+			rules: func(rules pairingRules) pairingRules {
+				rules.teams = solver.TeamPolicy{Mode: config.TeamPolicyRequireCross}
+				return rules
+			},
+		},
+		{
+			name:     "no free slot",
+			leftover: &types.Person{Email: "leftover@example.com", MaxSessionsPerWeek: 2},
+			busy:     true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pair := newPeople("pair", 2)
+			pair[0].Team = "payments"
+			pair[1].Skills = types.Skills{"go": 1}
+			cal := &fakeCalendar{busy: map[string][]*types.Range{}}
+			schedule := scheduleTuples(t, newTuples(pair), cal)
+			session := schedule.solution.Sessions[0]
+			if test.busy {
+				cal.busy[test.leftover.Email] = []*types.Range{alwaysBusy}
+			}
+			rules := newRules(append(pair, test.leftover))
+			if test.rules != nil {
+				rules = test.rules(rules)
+			}
+
+			unpaired := foldIntoTrios([]*types.Person{test.leftover}, schedule, rules)
+
+			if len(schedule.solution.Sessions) != 1 {
+				t.Fatalf("foldIntoTrios() left %d sessions, want 1", len(schedule.solution.Sessions))
+			}
+			trio := schedule.solution.Sessions[0]
+			if test.folded {
+				if len(unpaired) != 0 || len(trio.Reviewers.People) != 3 || trio.Reviewers.People[2] != test.leftover {
+					t.Errorf("foldIntoTrios() = %d unpaired, session of %v, want a trio with the leftover", len(unpaired), trio.Reviewers.Emails())
+				}
+				if schedule.weeks[trio] != 0 || !inWeek(trio, 0) {
+					t.Errorf("foldIntoTrios() scheduled the trio at %v, want the week of the pair", trio.Start())
+				}
+				return
+			}
+			if len(unpaired) != 1 || trio != session {
+				t.Errorf("foldIntoTrios() = %d unpaired, session of %v, want the leftover unpaired and the pair unchanged", len(unpaired), trio.Reviewers.Emails())
+			}
+		})
+	}
+}
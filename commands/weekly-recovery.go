@@ -0,0 +1,223 @@
+package commands
+
+import (
+	"matchmaker/libs/types"
+	"matchmaker/libs/util"
+	"slices"
+	"sort"
+	"time"
+)
+
+// Strategies rescuing the people of the pairs left without a session
+const (
+	// rescuedLaterWeek schedules the pair in one of the following weeks
+	rescuedLaterWeek = "laterWeek"
+
+	// rescuedRepaired pairs the person with another person left without a session
+	rescuedRepaired = "repaired"
+
+	// rescuedSwapped pairs the person with someone of a scheduled pair, whose
+	// partner is paired with another person left without a session
+	rescuedSwapped = "swapped"
+)
+
+// recoverUnmatchedTuples schedules the pairs left without a session by a
+// weekly match. Each pair is first tried in the recovery weeks following its
+// own week. The people of the pairs still failing are then re-paired with each
+// other, in any week of the match, and at last two of them swap partners with
+// a scheduled pair, in the week of that pair. Pairing rules apply to every new
+// pair. It reports the strategy rescuing each person, and returns the pairs
+// having a person still without a session, and these people.
+func recoverUnmatchedTuples(tuples types.Tuples, unmatched []types.Tuple, schedule *weeklySchedule, rules pairingRules, firstWeekShift int, cadence int, recoveryWeeks int) ([]types.Tuple, []*types.Person) {
+	rescued := make(map[*types.Person]string)
+	failed := make([]*types.Person, 0)
+	partners := make(map[*types.Person]*types.Person)
+
+	// Try the following weeks
+	for i, tuple := range tuples.Pairs {
+		if !slices.Contains(unmatched, tuple) {
+			continue
+		}
+		weekShift := firstWeekShift + i%cadence
+		if session, week := schedule.findInWeeks(tuple, weekShift+1, weekShift+recoveryWeeks, nil); session != nil {
+			schedule.add(session, week)
+			rescued[tuple.Person1], rescued[tuple.Person2] = rescuedLaterWeek, rescuedLaterWeek
+			util.LogInfo("Rescheduled tuple in a later week", map[string]interface{}{
+				"people":       tuple.Emails(),
+				"weekShift":    week,
+				"sessionStart": session.Start().Format(time.RFC3339),
+			})
+			continue
+		}
+		failed = append(failed, tuple.Person1, tuple.Person2)
+		partners[tuple.Person1], partners[tuple.Person2] = tuple.Person2, tuple.Person1
+	}
+
+	lastWeekShift := firstWeekShift + cadence - 1 + recoveryWeeks
+	repairFailedPeople(failed, partners, rescued, schedule, rules, firstWeekShift, lastWeekShift)
+	swapFailedPeople(failed, rescued, schedule, rules)
+
+	// A pair stays unmatched as long as one of its people is not rescued
+	stillUnmatched := make([]types.Tuple, 0)
+	for _, tuple := range unmatched {
+		if rescued[tuple.Person1] == "" || rescued[tuple.Person2] == "" {
+			stillUnmatched = append(stillUnmatched, tuple)
+		}
+	}
+	unmatchedPeople := make([]*types.Person, 0)
+	for _, person := range failed {
+		if rescued[person] == "" {
+			unmatchedPeople = append(unmatchedPeople, person)
+		}
+	}
+
+	reportRecovery(unmatched, rescued)
+	return stillUnmatched, unmatchedPeople
+}
+
+// repairFailedPeople pairs the people left without a session with each other,
+// other than their first partner. Partners are tried in a random order, the
+// least penalized by the pairing rules first, each in the weeks from the first
+// week shift to the last.
+func repairFailedPeople(failed []*types.Person, partners map[*types.Person]*types.Person, rescued map[*types.Person]string, schedule *weeklySchedule, rules pairingRules, firstWeekShift int, lastWeekShift int) {
+	people := slices.Clone(failed)
+	schedule.r.Shuffle(len(people), func(i, j int) {
+		people[i], people[j] = people[j], people[i]
+	})
+
+	for i, person := range people {
+		if rescued[person] != "" {
+			continue
+		}
+
+		candidates := make([]*types.Person, 0)
+		penalties := make(map[*types.Person]float64)
+		for _, other := range people[i+1:] {
+			if rescued[other] != "" || partners[person] == other {
+				continue
+			}
+			if penalty, ok := rules.lowestPenalty(person, other, firstWeekShift, lastWeekShift); ok {
+				candidates = append(candidates, other)
+				penalties[other] = penalty
+			}
+		}
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return penalties[candidates[i]] < penalties[candidates[j]]
+		})
+
+		for _, partner := range candidates {
+			tuple := types.Tuple{Person1: person, Person2: partner}
+			session, week := schedule.findInWeeks(tuple, firstWeekShift, lastWeekShift, rules.allows(tuple))
+			if session == nil {
+				continue
+			}
+			schedule.add(session, week)
+			rescued[person], rescued[partner] = rescuedRepaired, rescuedRepaired
+			util.LogInfo("Re-paired people left without a session", map[string]interface{}{
+				"people":       tuple.Emails(),
+				"weekShift":    week,
+				"sessionStart": session.Start().Format(time.RFC3339),
+			})
+			break
+		}
+	}
+}
+
+// swapFailedPeople pairs two people left without a session with the people of
+// a scheduled pair, one each, replacing the session of the pair by two
+// sessions in its week. Scheduled pairs are tried in a random order.
+func swapFailedPeople(failed []*types.Person, rescued map[*types.Person]string, schedule *weeklySchedule, rules pairingRules) {
+	for i, person := range failed {
+		if rescued[person] != "" {
+			continue
+		}
+		for _, other := range failed[i+1:] {
+			if rescued[other] == "" && swapWithScheduledPair(person, other, schedule, rules) {
+				rescued[person], rescued[other] = rescuedSwapped, rescuedSwapped
+				break
+			}
+		}
+	}
+}
+
+// swapWithScheduledPair swaps partners with the first scheduled pair allowing
+// it, and returns false if none does
+func swapWithScheduledPair(person *types.Person, other *types.Person, schedule *weeklySchedule, rules pairingRules) bool {
+	sessions := slices.Clone(schedule.solution.Sessions)
+	schedule.r.Shuffle(len(sessions), func(i, j int) {
+		sessions[i], sessions[j] = sessions[j], sessions[i]
+	})
+	for _, session := range sessions {
+		if swapPartners(person, other, session, schedule, rules) {
+			return true
+		}
+	}
+	return false
+}
+
+// swapPartners pairs the two people with the people of the scheduled pair, in
+// either order, and returns false if the pairing rules or the calendars of
+// the new pairs don't allow it. Required partners are never split.
+func swapPartners(person *types.Person, other *types.Person, session *types.ReviewSession, schedule *weeklySchedule, rules pairingRules) bool {
+	pair := session.Reviewers.People
+	if len(pair) != 2 || types.PairingRequired(pair) {
+		return false
+	}
+
+	for _, partners := range [][2]*types.Person{{pair[0], pair[1]}, {pair[1], pair[0]}} {
+		week := schedule.weeks[session]
+		_, ok1 := rules.penalty(person, partners[0], week)
+		_, ok2 := rules.penalty(other, partners[1], week)
+		if !ok1 || !ok2 {
+			continue
+		}
+
+		tuple1 := types.Tuple{Person1: person, Person2: partners[0]}
+		tuple2 := types.Tuple{Person1: other, Person2: partners[1]}
+		session1 := schedule.find(tuple1, week, session)
+		if session1 == nil {
+			continue
+		}
+		schedule.replace(session, session1)
+		session2 := schedule.find(tuple2, week)
+		if session2 == nil {
+			schedule.replace(session1, session)
+			continue
+		}
+		schedule.add(session2, week)
+
+		util.LogInfo("Swapped partners with a scheduled pair", map[string]interface{}{
+			"scheduledPair": []string{pair[0].Email, pair[1].Email},
+			"newPair1":      tuple1.Emails(),
+			"newPair2":      tuple2.Emails(),
+			"weekShift":     week,
+		})
+		return true
+	}
+	return false
+}
+
+// reportRecovery logs the strategy rescuing each person of the pairs left
+// without a session, none for the people still without one
+func reportRecovery(unmatched []types.Tuple, rescued map[*types.Person]string) {
+	counts := map[string]int{}
+	for _, tuple := range unmatched {
+		for _, person := range tuple.People() {
+			strategy := rescued[person]
+			if strategy == "" {
+				strategy = "none"
+			}
+			counts[strategy]++
+			util.LogInfo("Recovery of person", map[string]interface{}{
+				"email":    person.Email,
+				"strategy": strategy,
+			})
+		}
+	}
+	util.LogInfo("Recovery report", map[string]interface{}{
+		rescuedLaterWeek: counts[rescuedLaterWeek],
+		rescuedRepaired:  counts[rescuedRepaired],
+		rescuedSwapped:   counts[rescuedSwapped],
+		"none":           counts["none"],
+	})
+}
@@ -0,0 +1,166 @@
+package commands
+
+import (
+	"matchmaker/libs/types"
+	"matchmaker/libs/util"
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+)
+
+// busyWeek returns a busy range covering the week of the week shift
+func busyWeek(weekShift int) []*types.Range {
+	beginOfWeek := util.FirstDayOfISOWeek(weekShift)
+	return []*types.Range{{Start: beginOfWeek, End: beginOfWeek.AddDate(0, 0, 7)}}
+}
+
+// busyHalfDays returns busy ranges covering the mornings, or the afternoons,
+// of each day of the week of the week shift
+func busyHalfDays(weekShift int, mornings bool) []*types.Range {
+	ranges := []*types.Range{}
+	for day := util.FirstDayOfISOWeek(weekShift); day.Before(util.FirstDayOfISOWeek(weekShift + 1)); day = day.AddDate(0, 0, 1) {
+		noon := day.Add(12*time.Hour + 30*time.Minute)
+		if mornings {
+			ranges = append(ranges, &types.Range{Start: day, End: noon})
+		} else {
+			ranges = append(ranges, &types.Range{Start: noon, End: day.AddDate(0, 0, 1)})
+		}
+	}
+	return ranges
+}
+
+// recoverTuples schedules the tuples in the target week and recovers the pairs left
+// without a session
+func recoverTuples(t *testing.T, tuples types.Tuples, cal *fakeCalendar, recoveryWeeks int) (*weeklySchedule, []types.Tuple, []*types.Person) {
+	schedule := newWeeklySchedule(cal, rand.New(rand.NewSource(1)))
+	unmatched, _ := processTuplesAndCreateSessions(tuples, schedule, 0, 1)
+	if len(unmatched) == 0 {
+		t.Fatal("processTuplesAndCreateSessions() scheduled every pair, want pairs to recover")
+	}
+	people := []*types.Person{}
+	for _, tuple := range tuples.Pairs {
+		people = append(people, tuple.People()...)
+	}
+	stillUnmatched, unmatchedPeople := recoverUnmatchedTuples(tuples, unmatched, schedule, newRules(people), 0, 1, recoveryWeeks)
+	return schedule, stillUnmatched, unmatchedPeople
+}
+
+// sessionOf returns the session of the people, nil if they have none
+func sessionOf(schedule *weeklySchedule, people ...*types.Person) *types.ReviewSession {
+	for _, session := range schedule.solution.Sessions {
+		if len(session.Reviewers.People) == len(people) && !slices.ContainsFunc(people, func(person *types.Person) bool {
+			return !slices.Contains(session.Reviewers.People, person)
+		}) {
+			return session
+		}
+	}
+	return nil
+}
+
+func TestRecoverInLaterWeek(t *testing.T) {
+	defer setupWeeklyMatch()()
+
+	pair := newPeople("pair", 2)
+	cal := &fakeCalendar{busy: map[string][]*types.Range{pair[0].Email: busyWeek(0)}}
+	schedule, stillUnmatched, unmatchedPeople := recoverTuples(t, newTuples(pair), cal, 1)
+
+	session := sessionOf(schedule, pair...)
+	if session == nil || len(stillUnmatched) != 0 || len(unmatchedPeople) != 0 {
+		t.Fatalf("recoverUnmatchedTuples() = %v, %d unmatched, want the pair rescheduled", session, len(stillUnmatched))
+	}
+	if schedule.weeks[session] != 1 || !inWeek(session, 1) {
+		t.Errorf("recoverUnmatchedTuples() scheduled the pair at %v in week %d, want week 1", session.Start(), schedule.weeks[session])
+	}
+
+	// Without recovery weeks, the pair stays in its own week
+	_, stillUnmatched, unmatchedPeople = recoverTuples(t, newTuples(pair), cal, 0)
+	if len(stillUnmatched) != 1 || len(unmatchedPeople) != 2 {
+		t.Errorf("recoverUnmatchedTuples() without recovery weeks = %d unmatched pairs, %d people, want 1 and 2", len(stillUnmatched), len(unmatchedPeople))
+	}
+}
+
+func TestRecoverByRepairing(t *testing.T) {
+	defer setupWeeklyMatch()()
+
+	// The second person of each pair is never free
+	people := newPeople("person", 4)
+	cal := &fakeCalendar{busy: map[string][]*types.Range{
+		people[1].Email: {alwaysBusy},
+		people[3].Email: {alwaysBusy},
+	}}
+	tuples := newTuples(people)
+	schedule, stillUnmatched, unmatchedPeople := recoverTuples(t, tuples, cal, 0)
+
+	if session := sessionOf(schedule, people[0], people[2]); session == nil || !inWeek(session, 0) {
+		t.Errorf("recoverUnmatchedTuples() sessions = %d, want the free people re-paired in week 0", len(schedule.solution.Sessions))
+	}
+
+	// Pairs whose partner is still without a session are still reported
+	if len(stillUnmatched) != 2 {
+		t.Errorf("recoverUnmatchedTuples() = %d unmatched pairs, want 2", len(stillUnmatched))
+	}
+	if len(unmatchedPeople) != 2 || !slices.Contains(unmatchedPeople, people[1]) || !slices.Contains(unmatchedPeople, people[3]) {
+		t.Errorf("recoverUnmatchedTuples() unmatched people = %d, want the busy people", len(unmatchedPeople))
+	}
+}
+
+func TestRecoverBySwapping(t *testing.T) {
+	defer setupWeeklyMatch()()
+
+	// The scheduled pair is free, the other one can only meet in the morning
+	// for one, and in the afternoon for the other
+	scheduled := newPeople("scheduled", 2)
+	failed := newPeople("failed", 2)
+	cal := &fakeCalendar{busy: map[string][]*types.Range{
+		failed[0].Email: busyHalfDays(0, true),
+		failed[1].Email: busyHalfDays(0, false),
+	}}
+	tuples := newTuples(append(slices.Clone(scheduled), failed...))
+	schedule, stillUnmatched, unmatchedPeople := recoverTuples(t, tuples, cal, 0)
+
+	if len(stillUnmatched) != 0 || len(unmatchedPeople) != 0 {
+		t.Fatalf("recoverUnmatchedTuples() = %d unmatched pairs, %d people, want the failed pair swapped", len(stillUnmatched), len(unmatchedPeople))
+	}
+	if len(schedule.solution.Sessions) != 2 || sessionOf(schedule, scheduled...) != nil {
+		t.Fatalf("recoverUnmatchedTuples() sessions = %d, want the scheduled pair replaced by two pairs", len(schedule.solution.Sessions))
+	}
+	for _, session := range schedule.solution.Sessions {
+		people := session.Reviewers.People
+		if slices.Contains(people, failed[0]) == slices.Contains(people, failed[1]) {
+			t.Errorf("recoverUnmatchedTuples() scheduled %v, want each failed person with a scheduled one", session.Reviewers.Emails())
+		}
+		if schedule.weeks[session] != 0 || !inWeek(session, 0) {
+			t.Errorf("recoverUnmatchedTuples() scheduled %v at %v, want the week of the scheduled pair", session.Reviewers.Emails(), session.Start())
+		}
+	}
+}
+
+func TestSwapPartnersRollback(t *testing.T) {
+	defer setupWeeklyMatch()()
+
+	// The second failed person is never free, so that the first new pair is
+	// found and undone
+	scheduled := newPeople("scheduled", 2)
+	failed := newPeople("failed", 2)
+	cal := &fakeCalendar{busy: map[string][]*types.Range{failed[1].Email: {alwaysBusy}}}
+	schedule := scheduleTuples(t, newTuples(scheduled), cal)
+	session := schedule.solution.Sessions[0]
+
+	if swapPartners(failed[0], failed[1], session, schedule, newRules(append(slices.Clone(scheduled), failed...))) {
+		t.Fatal("swapPartners() swapped with a person never free")
+	}
+	if len(schedule.solution.Sessions) != 1 || schedule.solution.Sessions[0] != session {
+		t.Errorf("swapPartners() left %d sessions, want the scheduled pair restored", len(schedule.solution.Sessions))
+	}
+	if week, ok := schedule.weeks[session]; len(schedule.weeks) != 1 || !ok || week != 0 {
+		t.Errorf("swapPartners() left the weeks %v, want the week of the scheduled pair only", schedule.weeks)
+	}
+
+	// Required partners are never split
+	scheduled[0].PairWith = []string{scheduled[1].Email}
+	cal.busy = map[string][]*types.Range{}
+	if swapPartners(failed[0], failed[1], session, schedule, newRules(append(slices.Clone(scheduled), failed...))) {
+		t.Error("swapPartners() split required partners")
+	}
+}
@@ -2,6 +2,9 @@ package config
 
 import (
 	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
@@ -21,10 +24,193 @@ const (
 	SessionDurationMinutes           = "sessions.sessionDurationMinutes"
 	MinSessionSpacingHours           = "sessions.minSessionSpacingHours"
 	MaxSessionsPerPersonPerWeek      = "sessions.maxPerPersonPerWeek"
+	MaxSessionsPerPersonPerDay       = "sessions.maxPerPersonPerDay"
 	SessionPrefix                    = "sessions.sessionPrefix"
+	SquadSize                        = "sessions.squadSize"
 	Country                          = "country"
+	SolverMaxWidthExploration        = "solver.maxWidthExploration"
+	SolverMaxExplorationPathLength   = "solver.maxExplorationPathLength"
+	SolverCoveragePeriodMinutes      = "solver.coveragePeriodMinutes"
+	ObjectiveCoverageWeight          = "objective.coverageWeight"
+	ObjectiveFairnessWeight          = "objective.fairnessWeight"
+	ObjectiveReviewerLoadWeight      = "objective.reviewerLoadWeight"
+	ObjectiveRepetitionWeight        = "objective.repetitionWeight"
+	ObjectiveTeamWeight              = "objective.teamWeight"
+	HistoryFile                      = "history.file"
+	HistoryRecentWeeks               = "history.recentWeeks"
+	HistoryRepeatPolicy              = "history.repeatPolicy"
+	WeeklyMatchCadenceWeeks          = "weeklyMatch.cadenceWeeks"
+	WeeklyMatchLeftovers             = "weeklyMatch.leftovers"
+	WeeklyMatchRecovery              = "weeklyMatch.recovery"
+	WeeklyMatchRecoveryWeeks         = "weeklyMatch.recoveryWeeks"
+	RotationEnabled                  = "rotation.enabled"
+	RotationFile                     = "rotation.file"
+	Constraints                      = "constraints"
+	SlotScoringSection               = "slotScoring"
+	SeniorityLevels                  = "seniority.levels"
+	SeniorityGoodReviewerLevel       = "seniority.goodReviewerLevel"
+	SeniorityMinLevel                = "seniority.rules.minLevel"
+	SeniorityMaxLevelGap             = "seniority.rules.maxLevelGap"
+	TeamsPolicy                      = "teams.policy"
+	TeamsScope                       = "teams.scope"
+	SkillsMatching                   = "skills.matching"
+	SkillsExpertProficiency          = "skills.expertProficiency"
 )
 
+// AutoValue is the value of solver parameters picked from the size of the problem
+const AutoValue = "auto"
+
+// Policies applied to pairs of people paired within the recent weeks
+const (
+	RepeatPolicyPenalize = "penalize"
+	RepeatPolicyForbid   = "forbid"
+)
+
+// Policies applied to people of the same team
+const (
+	// TeamPolicyAny pairs people regardless of their team
+	TeamPolicyAny = "any"
+
+	// TeamPolicyPreferCross penalizes squads of people of the same team
+	TeamPolicyPreferCross = "preferCross"
+
+	// TeamPolicyRequireCross excludes squads of people of the same team
+	TeamPolicyRequireCross = "requireCross"
+
+	// TeamPolicySameTeam excludes squads of people of different teams
+	TeamPolicySameTeam = "sameTeam"
+)
+
+// Policies applied to the people left unpaired by a weekly match
+const (
+	// LeftoverPolicyExclude leaves unpaired people out of the week
+	LeftoverPolicyExclude = "exclude"
+
+	// LeftoverPolicyTrio adds unpaired people to a pair as a trio
+	LeftoverPolicyTrio = "trio"
+)
+
+// Scopes of the team policy
+const (
+	TeamScopeTeam       = "team"
+	TeamScopeDepartment = "department"
+)
+
+// Modes of matching people by skills
+const (
+	// SkillMatchingOverlap pairs people having skills with people sharing one
+	SkillMatchingOverlap = "overlap"
+
+	// SkillMatchingTransfer pairs an expert with a learner of the same skill
+	SkillMatchingTransfer = "transfer"
+
+	// SkillMatchingComplementary favors people knowing what the others don't
+	SkillMatchingComplementary = "complementary"
+)
+
+// Modes of planning constraints
+const (
+	ConstraintHard = "hard"
+	ConstraintSoft = "soft"
+	ConstraintOff  = "off"
+)
+
+// ConstraintSettings configures a planning constraint
+type ConstraintSettings struct {
+	// Mode is ConstraintHard, ConstraintSoft or ConstraintOff
+	Mode string
+
+	// Weight multiplies the violations of a soft constraint in the planning score
+	Weight float64
+}
+
+// SlotScoring configures the points given to the time of a session, the most
+// convenient slots getting the most points
+type SlotScoring struct {
+	// BeforeLunch and AfterLunch are given to sessions starting within the
+	// lunch window before or after the lunch break
+	BeforeLunch        int
+	AfterLunch         int
+	LunchWindowMinutes int
+
+	// Morning and Afternoon are given to sessions starting within the
+	// morning and afternoon working hours
+	Morning   int
+	Afternoon int
+
+	// EndOfDay is given to sessions starting within the last minutes of the
+	// afternoon, usually negative
+	EndOfDay        int
+	EndOfDayMinutes int
+
+	// AvoidedDay is given to sessions on one of the avoided days, usually
+	// negative, and OtherDay to sessions on other days
+	AvoidedDays []string
+	AvoidedDay  int
+	OtherDay    int
+
+	// MidWeek is given to sessions on one of the mid-week days
+	MidWeekDays []string
+	MidWeek     int
+
+	// NotPreferred is given for each person of the session who doesn't
+	// prefer its day, and for each one who doesn't prefer its hours
+	NotPreferred int
+}
+
+// DefaultSlotScoring is the slot scoring applied to the settings missing
+// from the configuration
+var DefaultSlotScoring = SlotScoring{
+	BeforeLunch:        25,
+	AfterLunch:         25,
+	LunchWindowMinutes: 60,
+	Morning:            10,
+	Afternoon:          10,
+	EndOfDay:           -20,
+	EndOfDayMinutes:    60,
+	AvoidedDays:        []string{"monday", "friday"},
+	AvoidedDay:         -15,
+	OtherDay:           5,
+	MidWeekDays:        []string{"tuesday", "wednesday", "thursday"},
+	MidWeek:            10,
+	NotPreferred:       -30,
+}
+
+// SenioritySettings configures the seniority levels and the rules squads
+// follow on the levels of their people
+type SenioritySettings struct {
+	// Levels lists the seniority levels, from the most junior one
+	Levels []string
+
+	// GoodReviewerLevel is the level of people flagged as good reviewers
+	// without a level
+	GoodReviewerLevel string
+
+	// MinLevel is the level at least one person of each squad has, no rule if empty
+	MinLevel string
+
+	// MaxLevelGap is the highest number of levels between the people of a
+	// squad, no rule if 0
+	MaxLevelGap int
+}
+
+// SkillMatching configures how people are matched by skills
+type SkillMatching struct {
+	// Mode is SkillMatchingOverlap, SkillMatchingTransfer or SkillMatchingComplementary
+	Mode string
+
+	// ExpertProficiency is the proficiency from which a person is an expert
+	// of a skill
+	ExpertProficiency int
+}
+
+// DefaultSkillMatching pairs people sharing skills, experts having a
+// proficiency of 3 or more
+var DefaultSkillMatching = SkillMatching{
+	Mode:              SkillMatchingOverlap,
+	ExpertProficiency: 3,
+}
+
 // WorkHoursConfig represents the configuration for work hours
 type WorkHoursConfig struct {
 	StartHour   int
@@ -74,16 +260,300 @@ func GetMaxSessionsPerPersonPerWeek() int {
 	return viper.GetInt(MaxSessionsPerPersonPerWeek)
 }
 
+// GetMaxSessionsPerPersonPerDay returns the maximum number of sessions a
+// person can have per day, 0 for no limit
+func GetMaxSessionsPerPersonPerDay() int {
+	return viper.GetInt(MaxSessionsPerPersonPerDay)
+}
+
 // GetSessionPrefix returns the prefix used for session event titles
 func GetSessionPrefix() string {
 	return viper.GetString(SessionPrefix)
 }
 
+// GetSquadSize returns the number of people attending each session
+func GetSquadSize() (int, error) {
+	return ValidateSquadSize(viper.GetInt(SquadSize))
+}
+
+// ValidateSquadSize checks that a squad size has at least two people
+func ValidateSquadSize(size int) (int, error) {
+	if size < 2 {
+		return 0, fmt.Errorf("invalid squad size %d: a squad must have at least 2 people", size)
+	}
+	return size, nil
+}
+
 // GetCountry returns the configured country code
 func GetCountry() string {
 	return viper.GetString(Country)
 }
 
+// GetMaxWidthExploration returns the number of alternatives explored at each
+// step of the search, or 0 if it should be picked automatically
+func GetMaxWidthExploration() (int, error) {
+	return ParseAutoInt(viper.GetString(SolverMaxWidthExploration))
+}
+
+// GetMaxExplorationPathLength returns the depth after which the search stops
+// exploring alternatives, or 0 if it should be picked automatically
+func GetMaxExplorationPathLength() (int, error) {
+	return ParseAutoInt(viper.GetString(SolverMaxExplorationPathLength))
+}
+
+// GetCoveragePeriod returns the granularity at which coverage is measured,
+// or 0 if it should be picked automatically
+func GetCoveragePeriod() (time.Duration, error) {
+	minutes, err := ParseAutoInt(viper.GetString(SolverCoveragePeriodMinutes))
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(minutes) * time.Minute, nil
+}
+
+// GetCoverageWeight returns the weight of missing coverage in the planning score
+func GetCoverageWeight() (float64, error) {
+	return getWeight(ObjectiveCoverageWeight)
+}
+
+// GetFairnessWeight returns the weight of the variance of per-person session
+// counts in the planning score
+func GetFairnessWeight() (float64, error) {
+	return getWeight(ObjectiveFairnessWeight)
+}
+
+// GetReviewerLoadWeight returns the weight of the load put on good reviewers
+// in the planning score
+func GetReviewerLoadWeight() (float64, error) {
+	return getWeight(ObjectiveReviewerLoadWeight)
+}
+
+// GetRepetitionWeight returns the weight of sessions pairing people who were
+// paired recently in the planning score
+func GetRepetitionWeight() (float64, error) {
+	return getWeight(ObjectiveRepetitionWeight)
+}
+
+// GetTeamWeight returns the weight of sessions pairing people of the same
+// team in the planning score, when the team policy prefers cross-team squads
+func GetTeamWeight() (float64, error) {
+	return getWeight(ObjectiveTeamWeight)
+}
+
+// getWeight reads a non-negative objective weight
+func getWeight(key string) (float64, error) {
+	weight := viper.GetFloat64(key)
+	if weight < 0 {
+		return 0, fmt.Errorf("invalid %s %v: must not be negative", key, weight)
+	}
+	return weight, nil
+}
+
+// GetHistoryFile returns the path of the pairing history file
+func GetHistoryFile() string {
+	return viper.GetString(HistoryFile)
+}
+
+// GetWeeklyMatchCadence returns the number of weeks the pairs of a weekly
+// match are spread over
+func GetWeeklyMatchCadence() (int, error) {
+	cadence := viper.GetInt(WeeklyMatchCadenceWeeks)
+	if cadence < 1 {
+		return 0, fmt.Errorf("invalid %s %d: must be at least 1", WeeklyMatchCadenceWeeks, cadence)
+	}
+	return cadence, nil
+}
+
+// GetLeftoverPolicy returns the policy applied to the people left unpaired by
+// a weekly match
+func GetLeftoverPolicy() (string, error) {
+	policy := viper.GetString(WeeklyMatchLeftovers)
+	if policy != LeftoverPolicyExclude && policy != LeftoverPolicyTrio {
+		return "", fmt.Errorf("invalid %s %q: must be %q or %q", WeeklyMatchLeftovers, policy, LeftoverPolicyExclude, LeftoverPolicyTrio)
+	}
+	return policy, nil
+}
+
+// IsWeeklyMatchRecoveryEnabled returns true if the pairs of a weekly match left
+// without a session are rescheduled, re-paired or swapped with other pairs
+func IsWeeklyMatchRecoveryEnabled() bool {
+	return viper.GetBool(WeeklyMatchRecovery)
+}
+
+// GetWeeklyMatchRecoveryWeeks returns the number of weeks after its own week
+// in which a pair left without a session is rescheduled
+func GetWeeklyMatchRecoveryWeeks() (int, error) {
+	weeks := viper.GetInt(WeeklyMatchRecoveryWeeks)
+	if weeks < 0 {
+		return 0, fmt.Errorf("invalid %s %d: must not be negative", WeeklyMatchRecoveryWeeks, weeks)
+	}
+	return weeks, nil
+}
+
+// IsRotationEnabled returns true if weekly matches follow a round robin
+// rotation instead of random pairs
+func IsRotationEnabled() bool {
+	return viper.GetBool(RotationEnabled)
+}
+
+// GetRotationFile returns the path of the rotation file of the weekly
+// matches of a group file: the configured file, suffixed with the name of the
+// group file, e.g. history/rotation-group.json for group.yml
+func GetRotationFile(groupFile string) string {
+	file := viper.GetString(RotationFile)
+	group := strings.TrimSuffix(filepath.Base(groupFile), filepath.Ext(groupFile))
+	ext := filepath.Ext(file)
+	return fmt.Sprintf("%s-%s%s", strings.TrimSuffix(file, ext), group, ext)
+}
+
+// GetRecentWeeks returns the number of weeks during which a past pairing is
+// considered recent
+func GetRecentWeeks() int {
+	return viper.GetInt(HistoryRecentWeeks)
+}
+
+// GetRepeatPolicy returns how recent pairs are handled, RepeatPolicyPenalize
+// or RepeatPolicyForbid
+func GetRepeatPolicy() (string, error) {
+	policy := viper.GetString(HistoryRepeatPolicy)
+	if policy != RepeatPolicyPenalize && policy != RepeatPolicyForbid {
+		return "", fmt.Errorf("invalid %s %q: must be %q or %q", HistoryRepeatPolicy, policy, RepeatPolicyPenalize, RepeatPolicyForbid)
+	}
+	return policy, nil
+}
+
+// GetConstraints returns the settings of the constraints listed in the
+// configuration, by name. Soft constraints weigh 1 unless a weight is set.
+func GetConstraints() (map[string]ConstraintSettings, error) {
+	settings := map[string]ConstraintSettings{}
+	for name := range viper.GetStringMap(Constraints) {
+		key := fmt.Sprintf("%s.%s", Constraints, name)
+		setting := ConstraintSettings{
+			Mode:   viper.GetString(key + ".mode"),
+			Weight: 1,
+		}
+		if setting.Mode != ConstraintHard && setting.Mode != ConstraintSoft && setting.Mode != ConstraintOff {
+			return nil, fmt.Errorf("invalid %s.mode %q: must be %q, %q or %q", key, setting.Mode, ConstraintHard, ConstraintSoft, ConstraintOff)
+		}
+		if viper.IsSet(key + ".weight") {
+			weight, err := getWeight(key + ".weight")
+			if err != nil {
+				return nil, err
+			}
+			setting.Weight = weight
+		}
+		settings[name] = setting
+	}
+	return settings, nil
+}
+
+// GetSlotScoring returns the slot scoring of the configuration, with the
+// default value of each setting it doesn't set
+func GetSlotScoring() SlotScoring {
+	scoring := DefaultSlotScoring
+	points := map[string]*int{
+		"beforeLunch":        &scoring.BeforeLunch,
+		"afterLunch":         &scoring.AfterLunch,
+		"lunchWindowMinutes": &scoring.LunchWindowMinutes,
+		"morning":            &scoring.Morning,
+		"afternoon":          &scoring.Afternoon,
+		"endOfDay":           &scoring.EndOfDay,
+		"endOfDayMinutes":    &scoring.EndOfDayMinutes,
+		"avoidedDay":         &scoring.AvoidedDay,
+		"otherDay":           &scoring.OtherDay,
+		"midWeek":            &scoring.MidWeek,
+		"notPreferred":       &scoring.NotPreferred,
+	}
+	for name, value := range points {
+		if key := SlotScoringSection + "." + name; viper.IsSet(key) {
+			*value = viper.GetInt(key)
+		}
+	}
+	if key := SlotScoringSection + ".avoidedDays"; viper.IsSet(key) {
+		scoring.AvoidedDays = viper.GetStringSlice(key)
+	}
+	if key := SlotScoringSection + ".midWeekDays"; viper.IsSet(key) {
+		scoring.MidWeekDays = viper.GetStringSlice(key)
+	}
+	return scoring
+}
+
+// GetTeamPolicy returns how people of the same team are paired, one of the
+// TeamPolicy values, and whether teams or departments are compared
+func GetTeamPolicy() (string, string, error) {
+	policy := viper.GetString(TeamsPolicy)
+	if policy != TeamPolicyAny && policy != TeamPolicyPreferCross && policy != TeamPolicyRequireCross && policy != TeamPolicySameTeam {
+		return "", "", fmt.Errorf("invalid %s %q: must be %q, %q, %q or %q", TeamsPolicy, policy, TeamPolicyAny, TeamPolicyPreferCross, TeamPolicyRequireCross, TeamPolicySameTeam)
+	}
+	scope := viper.GetString(TeamsScope)
+	if scope != TeamScopeTeam && scope != TeamScopeDepartment {
+		return "", "", fmt.Errorf("invalid %s %q: must be %q or %q", TeamsScope, scope, TeamScopeTeam, TeamScopeDepartment)
+	}
+	return policy, scope, nil
+}
+
+// GetSkillMatching returns how people are matched by skills, with the
+// default value of each setting the configuration doesn't set
+func GetSkillMatching() (SkillMatching, error) {
+	matching := DefaultSkillMatching
+	if viper.IsSet(SkillsMatching) {
+		matching.Mode = viper.GetString(SkillsMatching)
+	}
+	if viper.IsSet(SkillsExpertProficiency) {
+		matching.ExpertProficiency = viper.GetInt(SkillsExpertProficiency)
+	}
+	if matching.Mode != SkillMatchingOverlap && matching.Mode != SkillMatchingTransfer && matching.Mode != SkillMatchingComplementary {
+		return matching, fmt.Errorf("invalid %s %q: must be %q, %q or %q", SkillsMatching, matching.Mode, SkillMatchingOverlap, SkillMatchingTransfer, SkillMatchingComplementary)
+	}
+	if matching.ExpertProficiency <= 0 {
+		return matching, fmt.Errorf("invalid %s %d: must be positive", SkillsExpertProficiency, matching.ExpertProficiency)
+	}
+	return matching, nil
+}
+
+// GetSeniority returns the seniority settings of the configuration
+func GetSeniority() (SenioritySettings, error) {
+	settings := SenioritySettings{
+		Levels:            viper.GetStringSlice(SeniorityLevels),
+		GoodReviewerLevel: viper.GetString(SeniorityGoodReviewerLevel),
+		MinLevel:          viper.GetString(SeniorityMinLevel),
+		MaxLevelGap:       viper.GetInt(SeniorityMaxLevelGap),
+	}
+	if settings.MaxLevelGap < 0 {
+		return settings, fmt.Errorf("invalid %s %d: must be positive or 0 for no limit", SeniorityMaxLevelGap, settings.MaxLevelGap)
+	}
+	for key, level := range map[string]string{SeniorityGoodReviewerLevel: settings.GoodReviewerLevel, SeniorityMinLevel: settings.MinLevel} {
+		if level != "" && !containsFold(settings.Levels, level) {
+			return settings, fmt.Errorf("invalid %s %q: must be one of %s (%v)", key, level, SeniorityLevels, settings.Levels)
+		}
+	}
+	return settings, nil
+}
+
+// containsFold returns true if the value is in the list, case-insensitively
+func containsFold(values []string, value string) bool {
+	for _, candidate := range values {
+		if strings.EqualFold(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseAutoInt parses a positive integer parameter, returning 0 for an empty
+// value or AutoValue
+func ParseAutoInt(value string) (int, error) {
+	if value == "" || value == AutoValue {
+		return 0, nil
+	}
+
+	result, err := strconv.Atoi(value)
+	if err != nil || result <= 0 {
+		return 0, fmt.Errorf("invalid value %q: must be a positive integer or %q", value, AutoValue)
+	}
+	return result, nil
+}
+
 // validateTimeRange checks if the time range is valid
 func validateTimeRange(startHour, startMinute, endHour, endMinute int) error {
 	if startHour < 0 || startHour >= 24 || endHour < 0 || endHour >= 24 {
@@ -114,7 +584,9 @@ func Initialize() error {
 	viper.SetDefault(SessionDurationMinutes, 60)
 	viper.SetDefault(MinSessionSpacingHours, 8)
 	viper.SetDefault(MaxSessionsPerPersonPerWeek, 2)
+	viper.SetDefault(MaxSessionsPerPersonPerDay, 0)
 	viper.SetDefault(SessionPrefix, "Pairing")
+	viper.SetDefault(SquadSize, 2)
 
 	// Default working hours
 	viper.SetDefault(WorkingHoursTimezone, "Europe/Paris")
@@ -130,6 +602,47 @@ func Initialize() error {
 	// Set default values
 	viper.SetDefault(Country, "FR") // Default to France
 
+	// Default solver parameters
+	viper.SetDefault(SolverMaxWidthExploration, AutoValue)
+	viper.SetDefault(SolverMaxExplorationPathLength, AutoValue)
+	viper.SetDefault(SolverCoveragePeriodMinutes, AutoValue)
+
+	// Default objective weights: plannings only minimize missing coverage, as
+	// before fairness and reviewer load were scored, unless configured
+	viper.SetDefault(ObjectiveCoverageWeight, 1.0)
+	viper.SetDefault(ObjectiveFairnessWeight, 0.0)
+	viper.SetDefault(ObjectiveReviewerLoadWeight, 0.0)
+	viper.SetDefault(ObjectiveRepetitionWeight, 2.0)
+	viper.SetDefault(ObjectiveTeamWeight, 1.0)
+
+	// Default pairing history
+	viper.SetDefault(HistoryFile, "history/pairings.json")
+	viper.SetDefault(HistoryRecentWeeks, 4)
+	viper.SetDefault(HistoryRepeatPolicy, RepeatPolicyPenalize)
+
+	// Default weekly matches: every pair meets within the target week, and
+	// unpaired people are left out. Pairs left without a session are only
+	// recovered when enabled, trying the week after theirs.
+	viper.SetDefault(WeeklyMatchCadenceWeeks, 1)
+	viper.SetDefault(WeeklyMatchLeftovers, LeftoverPolicyExclude)
+	viper.SetDefault(WeeklyMatchRecovery, true)
+	viper.SetDefault(WeeklyMatchRecoveryWeeks, 1)
+
+	// Default rotation of weekly matches
+	viper.SetDefault(RotationEnabled, false)
+	viper.SetDefault(RotationFile, "history/rotation.json")
+
+	// Default team policy
+	viper.SetDefault(TeamsPolicy, TeamPolicyAny)
+	viper.SetDefault(TeamsScope, TeamScopeTeam)
+
+	// Default seniority: good reviewers are seniors, and every squad has a
+	// senior or more
+	viper.SetDefault(SeniorityLevels, []string{"junior", "mid", "senior", "staff"})
+	viper.SetDefault(SeniorityGoodReviewerLevel, "senior")
+	viper.SetDefault(SeniorityMinLevel, "senior")
+	viper.SetDefault(SeniorityMaxLevelGap, 0)
+
 	err := viper.ReadInConfig()
 	if err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
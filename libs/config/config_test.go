@@ -0,0 +1,43 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestWeeklyMatchValidators(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		value   interface{}
+		get     func() (interface{}, error)
+		want    interface{}
+		wantErr bool
+	}{
+		{name: "one week cadence", key: WeeklyMatchCadenceWeeks, value: 1, get: func() (interface{}, error) { return GetWeeklyMatchCadence() }, want: 1},
+		{name: "two weeks cadence", key: WeeklyMatchCadenceWeeks, value: 2, get: func() (interface{}, error) { return GetWeeklyMatchCadence() }, want: 2},
+		{name: "zero cadence", key: WeeklyMatchCadenceWeeks, value: 0, get: func() (interface{}, error) { return GetWeeklyMatchCadence() }, wantErr: true},
+		{name: "negative cadence", key: WeeklyMatchCadenceWeeks, value: -1, get: func() (interface{}, error) { return GetWeeklyMatchCadence() }, wantErr: true},
+		{name: "exclude leftovers", key: WeeklyMatchLeftovers, value: LeftoverPolicyExclude, get: func() (interface{}, error) { return GetLeftoverPolicy() }, want: LeftoverPolicyExclude},
+		{name: "trio leftovers", key: WeeklyMatchLeftovers, value: LeftoverPolicyTrio, get: func() (interface{}, error) { return GetLeftoverPolicy() }, want: LeftoverPolicyTrio},
+		{name: "unknown leftovers", key: WeeklyMatchLeftovers, value: "pair", get: func() (interface{}, error) { return GetLeftoverPolicy() }, wantErr: true},
+		{name: "no recovery weeks", key: WeeklyMatchRecoveryWeeks, value: 0, get: func() (interface{}, error) { return GetWeeklyMatchRecoveryWeeks() }, want: 0},
+		{name: "recovery weeks", key: WeeklyMatchRecoveryWeeks, value: 2, get: func() (interface{}, error) { return GetWeeklyMatchRecoveryWeeks() }, want: 2},
+		{name: "negative recovery weeks", key: WeeklyMatchRecoveryWeeks, value: -1, get: func() (interface{}, error) { return GetWeeklyMatchRecoveryWeeks() }, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			viper.Set(test.key, test.value)
+			defer viper.Set(test.key, nil)
+
+			got, err := test.get()
+			if (err != nil) != test.wantErr {
+				t.Fatalf("%s = %v: error = %v, wantErr %v", test.key, test.value, err, test.wantErr)
+			}
+			if !test.wantErr && got != test.want {
+				t.Errorf("%s = %v: got %v, want %v", test.key, test.value, got, test.want)
+			}
+		})
+	}
+}
@@ -2,10 +2,12 @@ package gcalendar
 
 import (
 	"fmt"
+	"matchmaker/libs/config"
 	"matchmaker/libs/types"
 	"matchmaker/libs/util"
 	"time"
 
+	"github.com/google/uuid"
 	"google.golang.org/api/calendar/v3"
 )
 
@@ -144,35 +146,72 @@ func (g *GCalendar) FindAvailableSlots(timeMin, timeMax time.Time, calendars []s
 	return availableSlots, nil
 }
 
-// CreateSessionEvent creates a session event in the calendar
-func (g *GCalendar) CreateSessionEvent(session *types.ReviewSession) (*calendar.Event, error) {
-	event := &calendar.Event{
-		Summary: session.GetEventSummary(),
+// CreateSessionEvent creates the event of a session, with a video
+// conference, in the calendar of its organizer. The description lists the
+// session times of each attendee in their own time zone.
+func (g *GCalendar) CreateSessionEvent(session *types.ReviewSession, organizerEmail string) (*calendar.Event, error) {
+	event := NewSessionEvent(session, organizerEmail)
+	return g.service.Events.Insert(SessionOrganizer(session, organizerEmail), event).ConferenceDataVersion(1).Do()
+}
+
+// SessionOrganizer returns the organizer of the event of a session: the
+// organizer email if set, otherwise the first person of the session
+func SessionOrganizer(session *types.ReviewSession, organizerEmail string) string {
+	if organizerEmail != "" {
+		return organizerEmail
+	}
+	return session.Reviewers.People[0].Email
+}
+
+// NewSessionEvent returns the event of a session. The organizer email, if
+// set, is added as an optional attendee.
+func NewSessionEvent(session *types.ReviewSession, organizerEmail string) *calendar.Event {
+	attendees := sessionAttendees(session)
+	if organizerEmail != "" {
+		attendees = append(attendees, &calendar.EventAttendee{
+			Email:    organizerEmail,
+			Optional: true,
+		})
+	}
+
+	return &calendar.Event{
 		Start: &calendar.EventDateTime{
-			DateTime: session.Range.Start.Format(time.RFC3339),
-			TimeZone: "UTC",
+			DateTime: FormatTime(session.Range.Start),
+			TimeZone: config.GetTimezone(),
 		},
 		End: &calendar.EventDateTime{
-			DateTime: session.Range.End.Format(time.RFC3339),
-			TimeZone: "UTC",
+			DateTime: FormatTime(session.Range.End),
+			TimeZone: config.GetTimezone(),
 		},
-		Attendees: []*calendar.EventAttendee{
-			{Email: session.Reviewers.People[0].Email},
-			{Email: session.Reviewers.People[1].Email},
-		},
-		Reminders: &calendar.EventReminders{
-			UseDefault: false,
-			Overrides: []*calendar.EventReminder{
-				{Method: "email", Minutes: 24 * 60},
-				{Method: "popup", Minutes: 10},
+		Summary:         session.GetEventSummary(),
+		Description:     session.GetEventDescription(),
+		Attendees:       attendees,
+		GuestsCanModify: true,
+		ConferenceData: &calendar.ConferenceData{
+			CreateRequest: &calendar.CreateConferenceRequest{
+				RequestId: uuid.New().String(),
+				ConferenceSolutionKey: &calendar.ConferenceSolutionKey{
+					Type: "hangoutsMeet",
+				},
+				Status: &calendar.ConferenceRequestStatus{
+					StatusCode: "success",
+				},
 			},
 		},
 	}
+}
 
-	return g.CreateEvent(event)
+// sessionAttendees returns an attendee for each person of the session squad
+func sessionAttendees(session *types.ReviewSession) []*calendar.EventAttendee {
+	attendees := make([]*calendar.EventAttendee, len(session.Reviewers.People))
+	for i, person := range session.Reviewers.People {
+		attendees[i] = &calendar.EventAttendee{Email: person.Email}
+	}
+	return attendees
 }
 
-// GetBusyTimesForPeople retrieves busy times for multiple people across work ranges
+// GetBusyTimesForPeople retrieves busy times for multiple people across work
+// ranges. Work ranges on days a person doesn't work are busy for them.
 func (g *GCalendar) GetBusyTimesForPeople(people []*types.Person, workRanges []*types.Range) []*types.BusyTime {
 	busyTimes := []*types.BusyTime{}
 	for _, person := range people {
@@ -180,6 +219,16 @@ func (g *GCalendar) GetBusyTimesForPeople(people []*types.Person, workRanges []*
 			continue
 		}
 		for _, workRange := range workRanges {
+			if !person.WorksOn(workRange.Start) {
+				util.LogInfo("Non-working day, range busy", map[string]interface{}{
+					"person": person.Email,
+				})
+				busyTimes = append(busyTimes, &types.BusyTime{
+					Person: person,
+					Range:  &types.Range{Start: workRange.Start, End: workRange.End},
+				})
+				continue
+			}
 			personBusyTimes, err := g.GetBusyTimes(person, workRange)
 			util.PanicOnError(err, "Cannot load busy times for person")
 			busyTimes = append(busyTimes, personBusyTimes...)
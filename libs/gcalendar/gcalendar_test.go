@@ -1,10 +1,18 @@
 package gcalendar
 
 import (
+	"context"
+	"encoding/json"
+	"io"
 	"matchmaker/libs/testutils"
 	"matchmaker/libs/types"
+	"net/http"
+	"strings"
 	"testing"
 	"time"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/option"
 )
 
 func TestFormatTime(t *testing.T) {
@@ -99,8 +107,9 @@ func TestCreateSessionEvent(t *testing.T) {
 	configMock.SetupWorkHours()
 	defer configMock.Restore()
 
-	// Create a mock calendar service
-	service, err := testutils.MockCalendarService()
+	// Record the requests sent to the calendar API
+	transport := &recordingTransport{}
+	service, err := calendar.NewService(context.Background(), option.WithHTTPClient(&http.Client{Transport: transport}))
 	if err != nil {
 		t.Fatalf("Failed to create mock calendar service: %v", err)
 	}
@@ -109,24 +118,85 @@ func TestCreateSessionEvent(t *testing.T) {
 	gcal := &GCalendar{service: service}
 
 	// Test parameters
+	start := time.Date(2024, 4, 1, 13, 0, 0, 0, time.UTC)
 	session := &types.ReviewSession{
 		Reviewers: &types.Squad{
 			People: []*types.Person{
-				{Email: "reviewer1@example.com"},
-				{Email: "reviewer2@example.com"},
+				{Email: "reviewer1@example.com", Timezone: "Europe/Paris"},
+				{Email: "reviewer2@example.com", Timezone: "America/Montreal"},
 			},
 		},
 		Range: &types.Range{
-			Start: time.Now().Add(1 * time.Hour),
-			End:   time.Now().Add(2 * time.Hour),
+			Start: start,
+			End:   start.Add(time.Hour),
 		},
 	}
 
 	// Call the function
-	_, err = gcal.CreateSessionEvent(session)
+	_, err = gcal.CreateSessionEvent(session, "organizer@example.com")
 	if err != nil {
 		t.Fatalf("CreateSessionEvent() error = %v", err)
 	}
+
+	// The event is inserted in the calendar of the organizer
+	if transport.request == nil || transport.request.Method != http.MethodPost {
+		t.Fatalf("CreateSessionEvent() sent %v, want an event insertion", transport.request)
+	}
+	if want := "/calendars/organizer@example.com/events"; !strings.HasSuffix(transport.request.URL.Path, want) {
+		t.Errorf("CreateSessionEvent() inserted the event in %s, want %s", transport.request.URL.Path, want)
+	}
+	if transport.request.URL.Query().Get("conferenceDataVersion") != "1" {
+		t.Error("CreateSessionEvent() didn't request a video conference")
+	}
+
+	// The inserted event carries the times of each attendee in their zone
+	var event calendar.Event
+	if err := json.Unmarshal(transport.body, &event); err != nil {
+		t.Fatalf("CreateSessionEvent() sent an invalid event: %v", err)
+	}
+	for _, want := range []string{
+		"reviewer1@example.com: Monday 2024-04-01 15:00 - 16:00 (Europe/Paris)",
+		"reviewer2@example.com: Monday 2024-04-01 09:00 - 10:00 (America/Montreal)",
+	} {
+		if !strings.Contains(event.Description, want) {
+			t.Errorf("CreateSessionEvent() description = %q, want it to contain %q", event.Description, want)
+		}
+	}
+	if len(event.Attendees) != 3 || !event.Attendees[2].Optional || event.Attendees[2].Email != "organizer@example.com" {
+		t.Errorf("CreateSessionEvent() attendees = %+v, want both reviewers and the optional organizer", event.Attendees)
+	}
+	if event.ConferenceData == nil || event.ConferenceData.CreateRequest == nil {
+		t.Error("CreateSessionEvent() sent an event without a video conference")
+	}
+
+	// Without an organizer email, the first reviewer organizes the event
+	if organizer := SessionOrganizer(session, ""); organizer != "reviewer1@example.com" {
+		t.Errorf("SessionOrganizer() = %s, want reviewer1@example.com", organizer)
+	}
+}
+
+// recordingTransport records the last request sent to the calendar API, and
+// answers with an empty response
+type recordingTransport struct {
+	request *http.Request
+	body    []byte
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.request = req
+	t.body = nil
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		t.body = body
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader("{}")),
+		Header:     make(http.Header),
+	}, nil
 }
 
 func TestGetBusyTimesForPeople(t *testing.T) {
@@ -164,6 +234,13 @@ func TestGetBusyTimesForPeople(t *testing.T) {
 	if busyTimes == nil {
 		t.Error("GetBusyTimesForPeople() returned nil busyTimes")
 	}
+
+	// Work ranges on non-working days are busy
+	people[0].WorkingDays = []string{start.AddDate(0, 0, 1).Weekday().String()}
+	busyTimes = gcal.GetBusyTimesForPeople(people, workRanges)
+	if len(busyTimes) != 1 || !busyTimes[0].Range.Start.Equal(start) || !busyTimes[0].Range.End.Equal(end) {
+		t.Errorf("GetBusyTimesForPeople() returned %d busy times, want the whole non-working day", len(busyTimes))
+	}
 }
 
 func TestParseTime(t *testing.T) {
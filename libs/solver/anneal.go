@@ -0,0 +1,98 @@
+package solver
+
+import (
+	"context"
+	"matchmaker/libs/types"
+	"math"
+	"math/rand"
+)
+
+const (
+	// annealIterations is the number of moves attempted by the simulated annealing
+	annealIterations = 20000
+
+	// annealInitialTemperature is the starting temperature, expressed in score
+	// units: early on, a move worsening the score by this much is accepted
+	// with a probability of about 1/e
+	annealInitialTemperature = 4.0
+
+	// annealCoolingRate is the factor applied to the temperature after each move
+	annealCoolingRate = 0.9997
+
+	// annealMaxAddAttempts is the number of random candidates tried when looking
+	// for a session that can be added to the current planning
+	annealMaxAddAttempts = 20
+)
+
+func init() {
+	Register("anneal", func() Solver { return &annealSolver{} })
+}
+
+// annealSolver runs a simulated annealing over plannings: it randomly adds or
+// removes sessions, always keeping improvements and sometimes accepting a
+// worse planning to escape local optima while the temperature is high.
+type annealSolver struct{}
+
+// Solve anneals from an empty planning and returns the best one encountered
+func (s *annealSolver) Solve(ctx context.Context, problem *types.Problem, options Options) (*Solution, *Stats) {
+	stats := &Stats{}
+	reporter := newProgressReporter(options.Progress)
+	r := rand.New(rand.NewSource(options.Seed))
+	sessions := candidateSessions(problem, options, r)
+	objective := newObjective(problem, options)
+
+	current := objective.newState(sessions)
+	currentScore, _ := objective.score(current)
+	bestSessions := current.Sessions()
+	bestScore := currentScore
+
+	temperature := annealInitialTemperature
+	for i := 0; i < annealIterations && len(sessions) > 0 && ctx.Err() == nil; i++ {
+		stats.Iterations += 1
+		temperature *= annealCoolingRate
+		reporter.report(stats.Iterations, bestScore, current.size())
+
+		neighbour := annealNeighbour(current, sessions, r)
+		if neighbour == nil {
+			continue
+		}
+
+		score, maxCoverage := objective.score(neighbour)
+		if maxCoverage > problem.MaxTotalCoverage {
+			continue
+		}
+
+		delta := score - currentScore
+		if delta > 0 && r.Float64() >= math.Exp(-delta/temperature) {
+			continue
+		}
+
+		current = neighbour
+		currentScore = score
+		if score < bestScore {
+			bestSessions = neighbour.Sessions()
+			bestScore = score
+		}
+	}
+
+	return &Solution{Sessions: bestSessions}, stats
+}
+
+// annealNeighbour returns the planning with one random session removed or one
+// random compatible session added, or nil if no move was found
+func annealNeighbour(current *searchState, sessions []*types.ReviewSession, r *rand.Rand) *searchState {
+	if current.size() > 0 && r.Intn(2) == 0 {
+		planned := current.Sessions()
+		removed := r.Intn(len(planned))
+		state := current.empty().withAll(planned[:removed])
+		return state.withAll(planned[removed+1:])
+	}
+
+	for attempt := 0; attempt < annealMaxAddAttempts; attempt++ {
+		session := sessions[r.Intn(len(sessions))]
+		if current.canAdd(session) {
+			return current.with(session)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package solver
+
+import (
+	"context"
+	"matchmaker/libs/types"
+	"matchmaker/libs/util"
+	"math/rand"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+func init() {
+	Register("beam", func() Solver { return &beamSolver{} })
+}
+
+// beamSolver explores the most promising partial plannings first, keeping
+// only the best few alternatives at each decision point. Sibling branches
+// are explored concurrently, up to one extra goroutine per CPU core.
+type beamSolver struct{}
+
+// Solve runs the beam search from an empty planning
+func (s *beamSolver) Solve(ctx context.Context, problem *types.Problem, options Options) (*Solution, *Stats) {
+	stats := &Stats{}
+	reporter := newProgressReporter(options.Progress)
+	sessions := candidateSessions(problem, options, rand.New(rand.NewSource(options.Seed)))
+	objective := newObjective(problem, options)
+	bestSessions, _ := getSolver(ctx, problem, sessions, objective, options.Parameters, stats, reporter)(objective.newState(sessions), "")
+	return &Solution{Sessions: bestSessions}, stats
+}
+
+type solver func(*searchState, string) ([]*types.ReviewSession, float64)
+
+type partialSolution struct {
+	state *searchState
+	score float64
+
+	// slots sums the slot scores of the sessions, breaking ties between
+	// partial solutions of equal score
+	slots int
+}
+
+type byScore []*partialSolution
+
+func (a byScore) Len() int      { return len(a) }
+func (a byScore) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a byScore) Less(i, j int) bool {
+	return a[i].score < a[j].score || a[i].score == a[j].score && a[i].slots > a[j].slots
+}
+
+// bestPlanning keeps track of the best planning found by concurrent explorations
+type bestPlanning struct {
+	mutex    sync.Mutex
+	sessions []*types.ReviewSession
+	score    float64
+	slots    int
+	path     string
+}
+
+// offer replaces the best planning if the given one is better. Between
+// plannings of equal score, the one with the best slots wins, then the one
+// found first by a sequential exploration, so that the result doesn't depend
+// on goroutine scheduling.
+func (b *bestPlanning) offer(solution *partialSolution, path string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	score, slots := solution.score, solution.slots
+	if score < b.score || score == b.score && (slots > b.slots || slots == b.slots && isPathBefore(path, b.path)) {
+		b.sessions = solution.state.Sessions()
+		b.score = score
+		b.slots = slots
+		b.path = path
+	}
+}
+
+// isPathBefore returns true if the exploration path a is visited before b
+// by a sequential depth first exploration
+func isPathBefore(a string, b string) bool {
+	aIndexes := strings.Split(a, "/")
+	bIndexes := strings.Split(b, "/")
+	for i := 1; i < len(aIndexes) && i < len(bIndexes); i++ {
+		aIndex, _ := strconv.Atoi(aIndexes[i])
+		bIndex, _ := strconv.Atoi(bIndexes[i])
+		if aIndex != bIndex {
+			return aIndex < bIndex
+		}
+	}
+	return len(aIndexes) < len(bIndexes)
+}
+
+// get returns the best planning found so far and its score
+func (b *bestPlanning) get() ([]*types.ReviewSession, float64) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.sessions, b.score
+}
+
+// getSolver returns a beam search function exploring plannings derived from
+// the given sessions, ranked by the objective, within the width and depth
+// limits of the parameters.
+// The search stops early when the context is done, and the best planning
+// found so far is returned. Progress is sent to the reporter after each
+// explored node. Between plannings of equal score, the ones with the most
+// convenient slots are preferred.
+func getSolver(ctx context.Context, problem *types.Problem, allSessions []*types.ReviewSession, objective *objective, parameters SearchParameters, stats *Stats, reporter *progressReporter) solver {
+	var solve solver
+
+	best := &bestPlanning{sessions: []*types.ReviewSession{}}
+	best.score, _ = objective.score(objective.newState(allSessions))
+
+	// slotScores rates each session once, it is read-only during the search
+	scorer := newSlotScorer()
+	slotScores := make(map[*types.ReviewSession]int, len(allSessions))
+	for _, session := range allSessions {
+		slotScores[session] = scorer.score(session)
+	}
+
+	// workers limits the number of goroutines exploring branches concurrently
+	workers := make(chan struct{}, runtime.NumCPU())
+
+	solve = func(state *searchState, path string) ([]*types.ReviewSession, float64) {
+		derivedSolutions := []*partialSolution{}
+		slots := 0
+		for _, session := range state.Sessions() {
+			slots += slotScores[session]
+		}
+
+		for _, session := range allSessions {
+			if ctx.Err() != nil {
+				break
+			}
+
+			if !state.canAdd(session) {
+				continue
+			}
+
+			newState := state.with(session)
+			newScore, newMaxCoverage := objective.score(newState)
+			if newMaxCoverage > problem.MaxTotalCoverage {
+				continue
+			}
+
+			derivedSolutions = append(derivedSolutions, &partialSolution{
+				state: newState,
+				score: newScore,
+				slots: slots + slotScores[session],
+			})
+		}
+
+		iterations := atomic.LoadInt64(&stats.Iterations)
+		_, bestScore := best.get()
+		util.LogDebug("Exploring children", map[string]interface{}{
+			"iterations": iterations,
+			"best":       bestScore,
+			"path":       path,
+			"children":   len(derivedSolutions),
+		})
+		reporter.report(iterations, bestScore, state.size())
+
+		if len(derivedSolutions) > 0 {
+			sort.Sort(byScore(derivedSolutions))
+
+			best.offer(derivedSolutions[0], path+"/0")
+
+			var waitGroup sync.WaitGroup
+			for i, derivedSolution := range derivedSolutions {
+				if ctx.Err() != nil || i >= parameters.MaxWidthExploration || i > 0 && len(path) > parameters.MaxExplorationPathLength {
+					break
+				}
+
+				atomic.AddInt64(&stats.Iterations, 1)
+
+				subPath := path + "/" + strconv.Itoa(i)
+
+				// explore in a new goroutine when a core is free, inline otherwise
+				select {
+				case workers <- struct{}{}:
+					waitGroup.Add(1)
+					go func(state *searchState, subPath string) {
+						defer waitGroup.Done()
+						defer func() { <-workers }()
+						solve(state, subPath)
+					}(derivedSolution.state, subPath)
+				default:
+					solve(derivedSolution.state, subPath)
+				}
+			}
+			waitGroup.Wait()
+		}
+
+		return best.get()
+	}
+	return solve
+}
@@ -0,0 +1,383 @@
+package solver
+
+import (
+	"fmt"
+	"matchmaker/libs/config"
+	"matchmaker/libs/types"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+func init() {
+	RegisterConstraint("busyTimes", func() Constraint { return busyTimesConstraint{} })
+	RegisterConstraint("skills", func() Constraint { return skillsConstraint{matching: skillMatching()} })
+	RegisterConstraint("spacing", func() Constraint { return spacingConstraint{spacing: config.GetMinSessionSpacing()} })
+	RegisterConstraint("weeklyLimit", func() Constraint { return weeklyLimitConstraint{} })
+	RegisterConstraint("dailyLimit", func() Constraint { return dailyLimitConstraint{limit: config.GetMaxSessionsPerPersonPerDay()} })
+	RegisterConstraint("blockedDays", func() Constraint { return blockedDaysConstraint{} })
+	RegisterSoftConstraint("preferredDays", func() Constraint { return preferredDaysConstraint{} }, 1)
+	RegisterSoftConstraint("preferredHours", func() Constraint { return preferredHoursConstraint{} }, 1)
+	RegisterSoftConstraint("complementarity", func() Constraint { return complementarityConstraint{matching: skillMatching()} }, 1)
+}
+
+// Planning gives constraints access to the sessions planned so far
+type Planning interface {
+	// Sessions returns the planned sessions
+	Sessions() []*types.ReviewSession
+
+	// PersonSessions returns the planned sessions attended by a person
+	PersonSessions(person *types.Person) []*types.ReviewSession
+}
+
+// Constraint is a rule on the sessions of a planning
+type Constraint interface {
+	// Violation measures how much adding the session to the planning breaks
+	// the rule, 0 when the rule is followed
+	Violation(planning Planning, session *types.ReviewSession) float64
+}
+
+// sessionList is a planning without index, for plannings of a few sessions
+type sessionList []*types.ReviewSession
+
+func (l sessionList) Sessions() []*types.ReviewSession {
+	return l
+}
+
+func (l sessionList) PersonSessions(person *types.Person) []*types.ReviewSession {
+	sessions := []*types.ReviewSession{}
+	for _, session := range l {
+		if slices.Contains(session.Reviewers.People, person) {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions
+}
+
+// Rule applies a constraint to the search
+type Rule struct {
+	Name       string
+	Constraint Constraint
+
+	// Hard rules reject the sessions violating them. Violations of soft rules
+	// are allowed but added to the planning score, multiplied by the weight.
+	Hard   bool
+	Weight float64
+}
+
+// HardRule returns a rule rejecting the sessions that violate the constraint
+func HardRule(name string, constraint Constraint) Rule {
+	return Rule{Name: name, Constraint: constraint, Hard: true}
+}
+
+// SoftRule returns a rule penalizing the sessions that violate the constraint
+func SoftRule(name string, constraint Constraint, weight float64) Rule {
+	return Rule{Name: name, Constraint: constraint, Weight: weight}
+}
+
+// Constraints are the rules followed by the sessions of a planning
+type Constraints []Rule
+
+// allows returns true if adding the session to the planning violates no hard rule
+func (c Constraints) allows(planning Planning, session *types.ReviewSession) bool {
+	for _, rule := range c {
+		if rule.Hard && rule.Constraint.Violation(planning, session) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// penalty returns the weighted violations of soft rules caused by adding the
+// session to the planning
+func (c Constraints) penalty(planning Planning, session *types.ReviewSession) float64 {
+	penalty := 0.0
+	for _, rule := range c {
+		if !rule.Hard && rule.Weight > 0 {
+			penalty += rule.Weight * rule.Constraint.Violation(planning, session)
+		}
+	}
+	return penalty
+}
+
+// isHard returns true if the named constraint is a hard rule
+func (c Constraints) isHard(name string) bool {
+	for _, rule := range c {
+		if rule.Name == name && rule.Hard {
+			return true
+		}
+	}
+	return false
+}
+
+// String lists the rules with their mode
+func (c Constraints) String() string {
+	names := make([]string, 0, len(c))
+	for _, rule := range c {
+		if rule.Hard {
+			names = append(names, rule.Name)
+		} else {
+			names = append(names, fmt.Sprintf("%s (soft, %g)", rule.Name, rule.Weight))
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// registeredConstraint is a constraint constructor with the rule applied when
+// the constraint is not configured
+type registeredConstraint struct {
+	factory func() Constraint
+	rule    Rule
+}
+
+// constraintRegistry maps constraint names to their constructors
+var constraintRegistry = map[string]registeredConstraint{}
+
+// RegisterConstraint makes a constraint available under the given name, as a
+// hard rule unless configured otherwise. It panics if a constraint with the
+// same name is already registered.
+func RegisterConstraint(name string, factory func() Constraint) {
+	registerConstraint(name, factory, Rule{Hard: true})
+}
+
+// RegisterSoftConstraint makes a constraint available under the given name, as
+// a soft rule of the given weight unless configured otherwise. It panics if a
+// constraint with the same name is already registered.
+func RegisterSoftConstraint(name string, factory func() Constraint, weight float64) {
+	registerConstraint(name, factory, Rule{Weight: weight})
+}
+
+func registerConstraint(name string, factory func() Constraint, rule Rule) {
+	if _, exists := constraintRegistry[name]; exists {
+		panic(fmt.Sprintf("constraint %q is already registered", name))
+	}
+	constraintRegistry[name] = registeredConstraint{factory: factory, rule: rule}
+}
+
+// ConstraintNames returns the sorted names of all registered constraints
+func ConstraintNames() []string {
+	names := make([]string, 0, len(constraintRegistry))
+	for name := range constraintRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DefaultConstraints returns every registered constraint with its default rule
+func DefaultConstraints() Constraints {
+	constraints, _ := NewConstraints(nil)
+	return constraints
+}
+
+// resolveConstraints returns the constraints to follow, falling back to the
+// default ones when none is set
+func resolveConstraints(constraints Constraints) Constraints {
+	if constraints == nil {
+		return DefaultConstraints()
+	}
+	return constraints
+}
+
+// NewConstraints builds the rules of the registered constraints from their
+// settings. Constraints without settings get their default rule. Names are
+// matched case-insensitively, since configuration keys are.
+func NewConstraints(settings map[string]config.ConstraintSettings) (Constraints, error) {
+	for name := range settings {
+		if findConstraint(name) == "" {
+			return nil, fmt.Errorf("unknown constraint %q (available: %s)", name, strings.Join(ConstraintNames(), ", "))
+		}
+	}
+
+	constraints := Constraints{}
+	for _, name := range ConstraintNames() {
+		registered := constraintRegistry[name]
+		constraint := registered.factory()
+		setting, ok := findSettings(settings, name)
+		switch {
+		case !ok:
+			rule := registered.rule
+			rule.Name = name
+			rule.Constraint = constraint
+			constraints = append(constraints, rule)
+		case setting.Mode == config.ConstraintHard:
+			constraints = append(constraints, HardRule(name, constraint))
+		case setting.Mode == config.ConstraintSoft:
+			constraints = append(constraints, SoftRule(name, constraint, setting.Weight))
+		}
+	}
+	return constraints, nil
+}
+
+// findConstraint returns the registered name matching the given one
+func findConstraint(name string) string {
+	for registered := range constraintRegistry {
+		if strings.EqualFold(registered, name) {
+			return registered
+		}
+	}
+	return ""
+}
+
+// findSettings returns the settings of the named constraint
+func findSettings(settings map[string]config.ConstraintSettings, name string) (config.ConstraintSettings, bool) {
+	for settingName, setting := range settings {
+		if strings.EqualFold(settingName, name) {
+			return setting, true
+		}
+	}
+	return config.ConstraintSettings{}, false
+}
+
+// busyTimesConstraint keeps sessions out of the busy times of their squad,
+// counting the overlapped busy ranges
+type busyTimesConstraint struct{}
+
+func (busyTimesConstraint) Violation(planning Planning, session *types.ReviewSession) float64 {
+	overlaps := 0
+	for _, busyRange := range session.Reviewers.BusyRanges {
+		if session.Range.Overlaps(busyRange) {
+			overlaps++
+		}
+	}
+	return float64(overlaps)
+}
+
+// skillsConstraint matches people by their skills, see SkillsViolation
+type skillsConstraint struct {
+	matching config.SkillMatching
+}
+
+func (c skillsConstraint) Violation(planning Planning, session *types.ReviewSession) float64 {
+	return SkillsViolation(c.matching, session.Reviewers.People)
+}
+
+// complementarityConstraint favors people learning the most from each other
+// with complementary matching, see ComplementarityViolation
+type complementarityConstraint struct {
+	matching config.SkillMatching
+}
+
+func (c complementarityConstraint) Violation(planning Planning, session *types.ReviewSession) float64 {
+	return ComplementarityViolation(c.matching, session.Reviewers.People)
+}
+
+// spacingConstraint keeps a minimum time between two sessions of a person,
+// counting the sessions too close to the new one
+type spacingConstraint struct {
+	spacing time.Duration
+}
+
+func (c spacingConstraint) Violation(planning Planning, session *types.ReviewSession) float64 {
+	paddedRange := session.Range.Pad(c.spacing)
+	tooClose := 0
+	for _, person := range session.Reviewers.People {
+		for _, otherSession := range planning.PersonSessions(person) {
+			if paddedRange.Overlaps(otherSession.Range) {
+				tooClose++
+			}
+		}
+	}
+	return float64(tooClose)
+}
+
+// weeklyLimitConstraint caps the number of sessions of each person per week,
+// counting the people exceeding their maximum
+type weeklyLimitConstraint struct{}
+
+func (weeklyLimitConstraint) Violation(planning Planning, session *types.ReviewSession) float64 {
+	exceeding := 0
+	for _, person := range session.Reviewers.People {
+		if len(planning.PersonSessions(person)) >= person.MaxSessionsPerWeek {
+			exceeding++
+		}
+	}
+	return float64(exceeding)
+}
+
+// dailyLimitConstraint caps the number of sessions of each person per day, in
+// their time zone, counting the people exceeding their maximum. People
+// without their own maximum follow the default limit, none if 0.
+type dailyLimitConstraint struct {
+	limit int
+}
+
+func (c dailyLimitConstraint) Violation(planning Planning, session *types.ReviewSession) float64 {
+	exceeding := 0
+	for _, person := range session.Reviewers.People {
+		limit := person.DailyLimit(c.limit)
+		if limit > 0 && sessionsOnDay(person, planning.PersonSessions(person), session.Start()) >= limit {
+			exceeding++
+		}
+	}
+	return float64(exceeding)
+}
+
+// sessionsOnDay counts the sessions on the day of the given time, in the time
+// zone of the person
+func sessionsOnDay(person *types.Person, sessions []*types.ReviewSession, date time.Time) int {
+	year, month, day := person.LocalTime(date).Date()
+	count := 0
+	for _, session := range sessions {
+		sessionYear, sessionMonth, sessionDay := person.LocalTime(session.Start()).Date()
+		if sessionYear == year && sessionMonth == month && sessionDay == day {
+			count++
+		}
+	}
+	return count
+}
+
+// busiestDay returns the highest number of sessions of a person on a single day
+func busiestDay(sessions []*types.ReviewSession) int {
+	planning := sessionList(sessions)
+	busiest := 0
+	for _, session := range sessions {
+		for _, person := range session.Reviewers.People {
+			busiest = max(busiest, sessionsOnDay(person, planning.PersonSessions(person), session.Start()))
+		}
+	}
+	return busiest
+}
+
+// blockedDaysConstraint keeps sessions out of the days blocked by their
+// people, counting the people blocking the day
+type blockedDaysConstraint struct{}
+
+func (blockedDaysConstraint) Violation(planning Planning, session *types.ReviewSession) float64 {
+	blocked := 0
+	for _, person := range session.Reviewers.People {
+		if person.IsBlockedOn(session.Start()) {
+			blocked++
+		}
+	}
+	return float64(blocked)
+}
+
+// preferredDaysConstraint plans sessions on the preferred days of their
+// people, counting the people not preferring the day
+type preferredDaysConstraint struct{}
+
+func (preferredDaysConstraint) Violation(planning Planning, session *types.ReviewSession) float64 {
+	missed := 0
+	for _, person := range session.Reviewers.People {
+		if !person.PrefersDay(session.Start()) {
+			missed++
+		}
+	}
+	return float64(missed)
+}
+
+// preferredHoursConstraint plans sessions within the preferred hours of their
+// people, counting the people not preferring the hours
+type preferredHoursConstraint struct{}
+
+func (preferredHoursConstraint) Violation(planning Planning, session *types.ReviewSession) float64 {
+	missed := 0
+	for _, person := range session.Reviewers.People {
+		if !person.PrefersHours(session.Range) {
+			missed++
+		}
+	}
+	return float64(missed)
+}
@@ -0,0 +1,254 @@
+package solver
+
+import (
+	"context"
+	"matchmaker/libs/config"
+	"matchmaker/libs/testutils"
+	"matchmaker/libs/types"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// noMondayConstraint forbids sessions on Mondays
+type noMondayConstraint struct{}
+
+func (noMondayConstraint) Violation(planning Planning, session *types.ReviewSession) float64 {
+	if session.Start().Weekday() == time.Monday {
+		return 1
+	}
+	return 0
+}
+
+func TestNewConstraints(t *testing.T) {
+	constraints, err := NewConstraints(nil)
+	if err != nil {
+		t.Fatalf("NewConstraints(nil) returned error: %v", err)
+	}
+	if got, want := constraints.String(), "blockedDays, busyTimes, complementarity (soft, 1), dailyLimit, preferredDays (soft, 1), preferredHours (soft, 1), skills, spacing, weeklyLimit"; got != want {
+		t.Errorf("NewConstraints(nil) = %s, want %s", got, want)
+	}
+
+	// Configuration keys are lower case
+	constraints, err = NewConstraints(map[string]config.ConstraintSettings{
+		"skills":         {Mode: config.ConstraintSoft, Weight: 2},
+		"spacing":        {Mode: config.ConstraintOff},
+		"preferreddays":  {Mode: config.ConstraintHard},
+		"preferredhours": {Mode: config.ConstraintOff},
+	})
+	if err != nil {
+		t.Fatalf("NewConstraints() returned error: %v", err)
+	}
+	if got, want := constraints.String(), "blockedDays, busyTimes, complementarity (soft, 1), dailyLimit, preferredDays, skills (soft, 2), weeklyLimit"; got != want {
+		t.Errorf("NewConstraints() = %s, want %s", got, want)
+	}
+	if constraints.isHard("skills") || !constraints.isHard("weeklyLimit") {
+		t.Errorf("NewConstraints() hard rules are wrong: %s", constraints)
+	}
+
+	if _, err := NewConstraints(map[string]config.ConstraintSettings{"unknown": {Mode: config.ConstraintHard}}); err == nil {
+		t.Error("NewConstraints() returned no error for an unknown constraint")
+	}
+}
+
+func TestSoftConstraints(t *testing.T) {
+	configMock := testutils.NewConfigMock()
+	configMock.SetupWorkHours()
+	defer configMock.Restore()
+
+	person1 := &types.Person{Email: "person1@example.com", MaxSessionsPerWeek: 1, Skills: types.Skills{"go": 1}}
+	person2 := &types.Person{Email: "person2@example.com", MaxSessionsPerWeek: 2, Skills: types.Skills{"java": 1}}
+	person3 := &types.Person{Email: "person3@example.com", MaxSessionsPerWeek: 2}
+
+	start := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	mismatch := &types.ReviewSession{
+		Reviewers: &types.Squad{People: []*types.Person{person1, person2}},
+		Range:     &types.Range{Start: start, End: start.Add(time.Hour)},
+	}
+	again := &types.ReviewSession{
+		Reviewers: &types.Squad{People: []*types.Person{person1, person3}},
+		Range:     &types.Range{Start: start.Add(2 * time.Hour), End: start.Add(3 * time.Hour)},
+	}
+	candidates := []*types.ReviewSession{mismatch, again}
+
+	spacing := spacingConstraint{spacing: 8 * time.Hour}
+
+	// Skills are checked even on an empty planning
+	hard := newSearchState(candidates, Constraints{
+		HardRule("skills", skillsConstraint{}),
+		HardRule("spacing", spacing),
+	})
+	if hard.canAdd(mismatch) {
+		t.Error("canAdd() accepted people without common skills")
+	}
+	if !hard.canAdd(again) {
+		t.Error("canAdd() rejected a session compatible with the empty planning")
+	}
+
+	state := newSearchState(candidates, Constraints{
+		HardRule("busyTimes", busyTimesConstraint{}),
+		SoftRule("skills", skillsConstraint{}, 2),
+		SoftRule("spacing", spacing, 1),
+		SoftRule("weeklyLimit", weeklyLimitConstraint{}, 0.5),
+	})
+	if !state.canAdd(mismatch) {
+		t.Fatal("canAdd() rejected a session only violating soft rules")
+	}
+	state = state.with(mismatch)
+	if state.penalty != 2 {
+		t.Errorf("penalty after a skills mismatch = %v, want 2", state.penalty)
+	}
+
+	// person1 exceeds their weekly maximum and has a session too close
+	if !state.canAdd(again) {
+		t.Fatal("canAdd() rejected a session only violating soft rules")
+	}
+	if state = state.with(again); state.penalty != 3.5 {
+		t.Errorf("penalty after exceeding limits = %v, want 3.5", state.penalty)
+	}
+}
+
+func TestSolveCustomConstraint(t *testing.T) {
+	configMock := testutils.NewConfigMock()
+	configMock.SetupWorkHours()
+	defer configMock.Restore()
+
+	// The test problem is on a Tuesday, make it start on the Monday before
+	problem := newTestProblem()
+	monday := &types.Range{
+		Start: problem.WorkRanges[0].Start.AddDate(0, 0, -1),
+		End:   problem.WorkRanges[0].End.AddDate(0, 0, -1),
+	}
+	problem.WorkRanges = append([]*types.Range{monday}, problem.WorkRanges...)
+	constraints := append(DefaultConstraints(), HardRule("noMonday", noMondayConstraint{}))
+
+	for _, name := range Algorithms() {
+		t.Run(name, func(t *testing.T) {
+			solution, stats, err := Solve(context.Background(), problem, Options{Algorithm: name, Constraints: constraints})
+			if err != nil {
+				t.Fatalf("Solve() returned error: %v", err)
+			}
+			if len(stats.Constraints) != len(constraints) {
+				t.Errorf("Solve() stats constraints = %s, want %s", stats.Constraints, constraints)
+			}
+			for _, session := range solution.Sessions {
+				if session.Start().Weekday() == time.Monday {
+					t.Errorf("Solve() planned a session on Monday at %v", session.Start())
+				}
+			}
+		})
+	}
+}
+
+func TestSolveComplementarity(t *testing.T) {
+	configMock := testutils.NewConfigMock()
+	configMock.SetupWorkHours()
+	defer configMock.Restore()
+	viper.Set(config.SkillsMatching, config.SkillMatchingComplementary)
+	defer viper.Set(config.SkillsMatching, nil)
+	viper.Set(config.SkillsExpertProficiency, 4)
+	defer viper.Set(config.SkillsExpertProficiency, nil)
+
+	// Squads include a good reviewer by default
+	expert := &types.Person{Email: "expert@example.com", IsGoodReviewer: true, MaxSessionsPerWeek: 1, Skills: types.Skills{"go": 4}}
+	intermediate := &types.Person{Email: "intermediate@example.com", MaxSessionsPerWeek: 1, Skills: types.Skills{"go": 3}}
+	novice := &types.Person{Email: "novice@example.com", MaxSessionsPerWeek: 1, Skills: types.Skills{"go": 1}}
+
+	// A single session fits in the work range
+	start := time.Date(2024, 4, 2, 9, 0, 0, 0, time.UTC)
+	problem := func(people ...*types.Person) *types.Problem {
+		return &types.Problem{
+			People:           people,
+			WorkRanges:       []*types.Range{{Start: start, End: start.Add(time.Hour)}},
+			TargetCoverage:   1,
+			MaxTotalCoverage: 1,
+		}
+	}
+
+	for _, name := range Algorithms() {
+		t.Run(name, func(t *testing.T) {
+			// Squads with a partial gap of proficiency are planned
+			solution, _, err := Solve(context.Background(), problem(expert, intermediate), Options{Algorithm: name})
+			if err != nil {
+				t.Fatalf("Solve() returned error: %v", err)
+			}
+			if len(solution.Sessions) != 1 {
+				t.Errorf("Solve() planned %d sessions for a partial gap, want 1", len(solution.Sessions))
+			}
+
+			// The squad with the largest gap is preferred
+			solution, _, err = Solve(context.Background(), problem(expert, intermediate, novice), Options{Algorithm: name})
+			if err != nil {
+				t.Fatalf("Solve() returned error: %v", err)
+			}
+			if len(solution.Sessions) != 1 || solution.Sessions[0].Reviewers.GetDisplayName() != "expert & novice" {
+				t.Errorf("Solve() planned %v, want expert & novice", solution.Sessions)
+			}
+		})
+	}
+}
+
+func TestTimePreferenceConstraints(t *testing.T) {
+	person1 := &types.Person{Email: "person1@example.com", MaxSessionsPerWeek: 2, BlockedDays: []string{"wednesday"}}
+	person2 := &types.Person{Email: "person2@example.com", MaxSessionsPerWeek: 2, PreferredDays: []string{"thursday"}, PreferredHours: []string{"14:00-18:00"}}
+	squad := &types.Squad{People: []*types.Person{person1, person2}}
+
+	wednesday := time.Date(2024, 4, 3, 10, 0, 0, 0, time.UTC)
+	tuesday := wednesday.AddDate(0, 0, -1)
+	thursday := wednesday.AddDate(0, 0, 1).Add(5 * time.Hour)
+	sessions := []*types.ReviewSession{
+		{Reviewers: squad, Range: &types.Range{Start: wednesday, End: wednesday.Add(time.Hour)}},
+		{Reviewers: squad, Range: &types.Range{Start: tuesday, End: tuesday.Add(time.Hour)}},
+		{Reviewers: squad, Range: &types.Range{Start: thursday, End: thursday.Add(time.Hour)}},
+	}
+
+	empty := newSearchState(sessions, DefaultConstraints())
+	if empty.canAdd(sessions[0]) {
+		t.Error("canAdd() accepted a session on a blocked day")
+	}
+	if penalty := empty.with(sessions[1]).penalty; penalty != 2 {
+		t.Errorf("penalty of a session outside preferred days and hours = %v, want 2", penalty)
+	}
+	if penalty := empty.with(sessions[2]).penalty; penalty != 0 {
+		t.Errorf("penalty of a session within preferences = %v, want 0", penalty)
+	}
+}
+
+func TestDailyLimitConstraint(t *testing.T) {
+	capped := &types.Person{Email: "capped@example.com", MaxSessionsPerWeek: 5, MaxSessionsPerDay: 1}
+	person1 := &types.Person{Email: "person1@example.com", MaxSessionsPerWeek: 5}
+	person2 := &types.Person{Email: "person2@example.com", MaxSessionsPerWeek: 5}
+
+	start := time.Date(2024, 4, 2, 9, 0, 0, 0, time.UTC)
+	session := func(people []*types.Person, start time.Time) *types.ReviewSession {
+		return &types.ReviewSession{
+			Reviewers: &types.Squad{People: people},
+			Range:     &types.Range{Start: start, End: start.Add(time.Hour)},
+		}
+	}
+	morning := session([]*types.Person{capped, person1}, start)
+	afternoon := session([]*types.Person{capped, person2}, start.Add(5*time.Hour))
+	nextDay := session([]*types.Person{capped, person2}, start.AddDate(0, 0, 1))
+	others := session([]*types.Person{person1, person2}, start.Add(5*time.Hour))
+	candidates := []*types.ReviewSession{morning, afternoon, nextDay, others}
+
+	// The person's own maximum applies without a global limit
+	state := newSearchState(candidates, Constraints{HardRule("dailyLimit", dailyLimitConstraint{})}).with(morning)
+	if state.canAdd(afternoon) {
+		t.Error("canAdd() accepted a second session on the day of a person capped at 1")
+	}
+	if !state.canAdd(nextDay) || !state.canAdd(others) {
+		t.Error("canAdd() rejected a session within the daily limits")
+	}
+
+	// The global limit applies to people without their own maximum
+	state = newSearchState(candidates, Constraints{HardRule("dailyLimit", dailyLimitConstraint{limit: 1})}).with(morning)
+	if state.canAdd(others) {
+		t.Error("canAdd() accepted a second session on the day of a person under the global limit")
+	}
+
+	if got := busiestDay([]*types.ReviewSession{morning, others, nextDay}); got != 2 {
+		t.Errorf("busiestDay() = %d, want 2", got)
+	}
+}
@@ -0,0 +1,239 @@
+package solver
+
+import (
+	"context"
+	"matchmaker/libs/types"
+	"matchmaker/libs/util"
+	"math/rand"
+	"time"
+)
+
+const (
+	// exactRecommendedMaxPeople is the group size above which the exact search
+	// is unlikely to complete and a warning is logged
+	exactRecommendedMaxPeople = 15
+
+	// exactMaxIterations caps the number of nodes explored by the exact search.
+	// When it is reached, or when the search is cancelled, the best planning
+	// found so far is returned without optimality proof.
+	exactMaxIterations = 200000
+)
+
+func init() {
+	Register("exact", func() Solver { return &exactSolver{} })
+}
+
+// exactSolver runs a depth-first branch and bound over combinations of
+// sessions. Branches whose score lower bound, derived from the coverage they
+// can still reach, cannot beat the best planning found so far are pruned, so
+// when the search completes the returned planning is proven optimal.
+type exactSolver struct{}
+
+// Solve starts from the greedy planning and explores every combination of
+// candidate sessions that could still improve it
+func (s *exactSolver) Solve(ctx context.Context, problem *types.Problem, options Options) (*Solution, *Stats) {
+	stats := &Stats{}
+
+	if len(problem.People) > exactRecommendedMaxPeople {
+		util.LogInfo("Exact search is not recommended for large groups and may stop before proving optimality", map[string]interface{}{
+			"people":             len(problem.People),
+			"recommendedMaximum": exactRecommendedMaxPeople,
+		})
+	}
+
+	objective := newObjective(problem, options)
+	candidates := candidateSessions(problem, options, rand.New(rand.NewSource(options.Seed)))
+	sessions := feasibleSessions(objective.newState(candidates), candidates)
+	search := newBranchAndBound(ctx, problem, sessions, objective, stats)
+	search.reporter = newProgressReporter(options.Progress)
+	search.bestSessions = greedySessions(ctx, problem, search.sessions, options, &Stats{}, nil)
+	search.bestScore = objective.evaluate(search.bestSessions).Score
+
+	search.explore(objective.newState(search.sessions), 0)
+
+	stats.Optimal = !search.exhausted
+	util.LogInfo("Exact search completed", map[string]interface{}{
+		"iterations": stats.Iterations,
+		"score":      search.bestScore,
+		"optimal":    stats.Optimal,
+	})
+
+	return &Solution{Sessions: search.bestSessions}, stats
+}
+
+// feasibleSessions keeps the candidate sessions that can be added to the
+// empty planning, i.e. that violate no hard rule on their own
+func feasibleSessions(empty *searchState, candidates []*types.ReviewSession) []*types.ReviewSession {
+	feasible := []*types.ReviewSession{}
+	for _, session := range candidates {
+		if empty.canAdd(session) {
+			feasible = append(feasible, session)
+		}
+	}
+	return feasible
+}
+
+// branchAndBound holds the state of an exact search
+type branchAndBound struct {
+	ctx      context.Context
+	problem  *types.Problem
+	sessions []*types.ReviewSession
+
+	// reachable[i][period] is the number of sessions with index >= i covering period
+	reachable [][]int
+
+	// periodsPerSession is the largest number of coverage periods spanned by a session
+	periodsPerSession int
+	periodSpan        time.Duration
+	objective         *objective
+
+	// peoplePerSession is the smallest number of people attending a session
+	peoplePerSession int
+
+	// weeklyLimited is true when weekly caps are a hard rule, so that they
+	// bound the number of sessions left to plan
+	weeklyLimited bool
+
+	bestSessions []*types.ReviewSession
+	bestScore    float64
+	stats        *Stats
+	reporter     *progressReporter
+	exhausted    bool
+}
+
+func newBranchAndBound(ctx context.Context, problem *types.Problem, sessions []*types.ReviewSession, objective *objective, stats *Stats) *branchAndBound {
+	periodSpan := objective.periodSpan
+	periodCount := 0
+	if len(problem.WorkRanges) > 0 {
+		coverage, _ := getCoverage(problem.WorkRanges, []*types.ReviewSession{}, periodSpan)
+		for period := range coverage {
+			if period+1 > periodCount {
+				periodCount = period + 1
+			}
+		}
+	}
+
+	search := &branchAndBound{
+		ctx:        ctx,
+		problem:    problem,
+		sessions:   sessions,
+		reachable:  make([][]int, len(sessions)+1),
+		periodSpan: periodSpan,
+		objective:  objective,
+		stats:      stats,
+	}
+
+	search.weeklyLimited = objective.constraints.isHard("weeklyLimit")
+	search.peoplePerSession = types.MinSquadSize
+	for i, session := range sessions {
+		if i == 0 || len(session.Reviewers.People) < search.peoplePerSession {
+			search.peoplePerSession = len(session.Reviewers.People)
+		}
+	}
+
+	search.reachable[len(sessions)] = make([]int, periodCount)
+	for i := len(sessions) - 1; i >= 0; i-- {
+		search.reachable[i] = make([]int, periodCount)
+		copy(search.reachable[i], search.reachable[i+1])
+
+		periods := sessionPeriods(problem.WorkRanges, sessions[i], periodSpan)
+		for _, period := range periods {
+			if period >= 0 && period < periodCount {
+				search.reachable[i][period] += 1
+			}
+		}
+		if len(periods) > search.periodsPerSession {
+			search.periodsPerSession = len(periods)
+		}
+	}
+
+	return search
+}
+
+// sessionPeriods returns the coverage periods spanned by a session
+func sessionPeriods(workRanges []*types.Range, session *types.ReviewSession, periodSpan time.Duration) []int {
+	periods := []int{}
+	date := session.Start()
+	for date.Before(session.End()) {
+		periods = append(periods, getCoveragePeriodId(workRanges, date, periodSpan))
+		date = date.Add(periodSpan)
+	}
+	return periods
+}
+
+// explore tries to extend the current sessions with sessions of index >= from
+func (b *branchAndBound) explore(state *searchState, from int) {
+	if b.stats.Iterations >= exactMaxIterations || b.ctx.Err() != nil {
+		b.exhausted = true
+		return
+	}
+	b.stats.Iterations += 1
+	b.reporter.report(b.stats.Iterations, b.bestScore, state.size())
+
+	coverage := state.coverageByPeriod()
+	missingCoverage := state.missingCoverage
+	remainingSessions := b.remainingSessions(state)
+
+	for i := from; i < len(b.sessions); i++ {
+		if b.bestScore == 0 || b.exhausted {
+			return
+		}
+
+		// bounds only grow with i as fewer sessions remain reachable, so no
+		// later branch can improve either
+		if b.objective.coverageScore(b.lowerBound(coverage, missingCoverage, remainingSessions, i)) >= b.bestScore {
+			return
+		}
+
+		session := b.sessions[i]
+		if !state.canAdd(session) {
+			continue
+		}
+
+		newState := state.with(session)
+		newScore, newMaxCoverage := b.objective.score(newState)
+		if newMaxCoverage > b.problem.MaxTotalCoverage {
+			continue
+		}
+
+		if newScore < b.bestScore {
+			b.bestSessions = newState.Sessions()
+			b.bestScore = newScore
+		}
+
+		b.explore(newState, i+1)
+	}
+}
+
+// lowerBound returns the lowest missing coverage reachable by adding sessions
+// of index >= from to a planning with the given coverage. Each missing period
+// can only be filled by the remaining sessions covering it, and the number of
+// sessions that can still be added is limited by people's weekly caps.
+func (b *branchAndBound) lowerBound(coverage map[int]int, missingCoverage int, remainingSessions int, from int) int {
+	reachable := b.reachable[from]
+
+	reachableDeficit := 0
+	for period, value := range coverage {
+		if value >= b.problem.TargetCoverage || period < 0 || period >= len(reachable) {
+			continue
+		}
+		reachableDeficit += min(b.problem.TargetCoverage-value, reachable[period])
+	}
+
+	maxSessions := min(remainingSessions, len(b.sessions)-from)
+	return missingCoverage - min(reachableDeficit, maxSessions*b.periodsPerSession)
+}
+
+// remainingSessions returns how many sessions can still be planned given the
+// weekly caps of people and the sessions already planned
+func (b *branchAndBound) remainingSessions(state *searchState) int {
+	if !b.weeklyLimited {
+		return len(b.sessions)
+	}
+
+	remainingSlots := 0
+	for _, person := range b.problem.People {
+		remainingSlots += max(0, person.MaxSessionsPerWeek-state.sessionCount(person))
+	}
+	return remainingSlots / b.peoplePerSession
+}
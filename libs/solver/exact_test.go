@@ -0,0 +1,82 @@
+package solver
+
+import (
+	"context"
+	"matchmaker/libs/testutils"
+	"matchmaker/libs/types"
+	"testing"
+	"time"
+)
+
+func TestExactSolver(t *testing.T) {
+	configMock := testutils.NewConfigMock()
+	configMock.SetupWorkHours()
+	defer configMock.Restore()
+
+	problem := newTestProblem()
+
+	_, exactStats, err := Solve(context.Background(), problem, Options{Algorithm: "exact"})
+	if err != nil {
+		t.Fatalf("Solve() returned error: %v", err)
+	}
+	if !exactStats.Optimal {
+		t.Error("exact solver did not prove optimality on a small problem")
+	}
+
+	for _, name := range []string{"beam", "greedy"} {
+		_, stats, err := Solve(context.Background(), newTestProblem(), Options{Algorithm: name})
+		if err != nil {
+			t.Fatalf("Solve() returned error: %v", err)
+		}
+		if exactStats.MissingCoverage > stats.MissingCoverage {
+			t.Errorf("exact missing coverage %d is worse than %s missing coverage %d",
+				exactStats.MissingCoverage, name, stats.MissingCoverage)
+		}
+		if stats.Optimal {
+			t.Errorf("%s solver claims a proven optimal planning", name)
+		}
+	}
+}
+
+func TestBranchAndBoundLowerBound(t *testing.T) {
+	start := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	person1 := &types.Person{Email: "person1@example.com", MaxSessionsPerWeek: 1}
+	person2 := &types.Person{Email: "person2@example.com", MaxSessionsPerWeek: 1}
+	squad := &types.Squad{People: []*types.Person{person1, person2}}
+
+	problem := &types.Problem{
+		People: []*types.Person{person1, person2},
+		WorkRanges: []*types.Range{
+			{Start: start, End: start.Add(2 * time.Hour)},
+		},
+		TargetCoverage:   1,
+		MaxTotalCoverage: 2,
+	}
+	sessions := []*types.ReviewSession{
+		{Reviewers: squad, Range: &types.Range{Start: start, End: start.Add(time.Hour)}},
+		{Reviewers: squad, Range: &types.Range{Start: start.Add(time.Hour), End: start.Add(2 * time.Hour)}},
+	}
+
+	search := newBranchAndBound(context.Background(), problem, sessions, newObjective(problem, Options{Parameters: SearchParameters{CoveragePeriodSpan: defaultCoveragePeriodSpan}}), &Stats{})
+	empty := newSearchState(sessions, DefaultConstraints())
+	coverage, _ := getCoverage(problem.WorkRanges, []*types.ReviewSession{}, defaultCoveragePeriodSpan)
+	missingCoverage := getMissingConverage(coverage, problem.TargetCoverage)
+
+	// Both people can only attend one session, so only 2 of the 4 periods can be covered
+	if got := search.lowerBound(coverage, missingCoverage, search.remainingSessions(empty), 0); got != 2 {
+		t.Errorf("lowerBound() from 0 = %d, want 2", got)
+	}
+
+	// No session remains after the last index
+	if got := search.lowerBound(coverage, missingCoverage, search.remainingSessions(empty), 2); got != 4 {
+		t.Errorf("lowerBound() from 2 = %d, want 4", got)
+	}
+
+	// Once a session is planned, caps prevent any further improvement
+	planned := sessions[:1]
+	coverage, _ = getCoverage(problem.WorkRanges, planned, defaultCoveragePeriodSpan)
+	missingCoverage = getMissingConverage(coverage, problem.TargetCoverage)
+	if got := search.lowerBound(coverage, missingCoverage, search.remainingSessions(empty.withAll(planned)), 1); got != 2 {
+		t.Errorf("lowerBound() after planning a session = %d, want 2", got)
+	}
+}
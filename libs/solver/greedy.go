@@ -0,0 +1,67 @@
+package solver
+
+import (
+	"context"
+	"matchmaker/libs/types"
+	"math/rand"
+)
+
+func init() {
+	Register("greedy", func() Solver { return &greedySolver{} })
+}
+
+// greedySolver repeatedly adds the session that reduces the planning score the
+// most, and stops as soon as no compatible session improves the planning.
+// It is the fastest strategy but never revisits a choice.
+type greedySolver struct{}
+
+// Solve builds a planning one best session at a time
+func (s *greedySolver) Solve(ctx context.Context, problem *types.Problem, options Options) (*Solution, *Stats) {
+	stats := &Stats{}
+	sessions := greedySessions(ctx, problem, candidateSessions(problem, options, rand.New(rand.NewSource(options.Seed))), options, stats, newProgressReporter(options.Progress))
+	return &Solution{Sessions: sessions}, stats
+}
+
+// greedySessions builds a planning by adding the best compatible session
+// among the candidates until the score stops decreasing or the context is
+// done
+func greedySessions(ctx context.Context, problem *types.Problem, sessions []*types.ReviewSession, options Options, stats *Stats, reporter *progressReporter) []*types.ReviewSession {
+	objective := newObjective(problem, options)
+
+	state := objective.newState(sessions)
+	currentScore, _ := objective.score(state)
+
+	for ctx.Err() == nil {
+		var bestState *searchState
+		bestScore := currentScore
+
+		for _, session := range sessions {
+			stats.Iterations += 1
+
+			if !state.canAdd(session) {
+				continue
+			}
+
+			newState := state.with(session)
+			score, maxCoverage := objective.score(newState)
+			if maxCoverage > problem.MaxTotalCoverage {
+				continue
+			}
+
+			if score < bestScore {
+				bestState = newState
+				bestScore = score
+			}
+		}
+
+		if bestState == nil {
+			break
+		}
+
+		state = bestState
+		currentScore = bestScore
+		reporter.report(stats.Iterations, currentScore, state.size())
+	}
+
+	return state.Sessions()
+}
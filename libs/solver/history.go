@@ -0,0 +1,54 @@
+package solver
+
+import (
+	"matchmaker/libs/types"
+	"time"
+)
+
+// RepeatPolicy controls how squads of people paired within the recent weeks
+// of the pairing history are handled
+type RepeatPolicy struct {
+	// History lists past pairings, repeats are ignored when nil
+	History *types.PairingHistory
+
+	// Weeks is the number of weeks before the planned week during which a
+	// pairing is considered recent
+	Weeks int
+
+	// Forbid excludes recent pairs instead of penalizing them
+	Forbid bool
+}
+
+// Penalty measures how recently any two of the people were paired before the
+// reference date, from 0 when none was paired recently to 1 when two of them
+// were paired during the last week
+func (p RepeatPolicy) Penalty(people []*types.Person, reference time.Time) float64 {
+	penalty := 0.0
+	for i := range people {
+		for j := i + 1; j < len(people); j++ {
+			penalty = max(penalty, p.History.Recency(people[i].Email, people[j].Email, reference, p.Weeks))
+		}
+	}
+	return penalty
+}
+
+// Allows returns false if the people can't be paired under the policy
+func (p RepeatPolicy) Allows(people []*types.Person, reference time.Time) bool {
+	return !p.Forbid || p.Penalty(people, reference) == 0
+}
+
+// problemStart returns the start of the earliest work range of the problem,
+// or the current time if it has none
+func problemStart(problem *types.Problem) time.Time {
+	if len(problem.WorkRanges) == 0 {
+		return time.Now()
+	}
+
+	start := problem.WorkRanges[0].Start
+	for _, workRange := range problem.WorkRanges {
+		if workRange.Start.Before(start) {
+			start = workRange.Start
+		}
+	}
+	return start
+}
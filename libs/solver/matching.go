@@ -0,0 +1,189 @@
+package solver
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// MatchingEdge is a possible pair of a matching, between the vertices A and B
+type MatchingEdge struct {
+	A, B int
+
+	// Penalty ranks the edge, lower penalties being picked first
+	Penalty float64
+}
+
+// MaximumMatching pairs as many of the n vertices as possible along the
+// edges, and returns the pairs. Among maximum matchings, edges of lower
+// penalty are picked first, ties being broken with the random generator.
+//
+// Edges are fixed one at a time, each one only when a maximum matching
+// including every fixed edge still exists, which Edmonds' blossom algorithm
+// checks on the vertices left.
+func MaximumMatching(n int, edges []MatchingEdge, r *rand.Rand) [][2]int {
+	candidates := append([]MatchingEdge{}, edges...)
+	r.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Penalty < candidates[j].Penalty
+	})
+
+	adjacency := make([][]int, n)
+	for _, edge := range candidates {
+		adjacency[edge.A] = append(adjacency[edge.A], edge.B)
+		adjacency[edge.B] = append(adjacency[edge.B], edge.A)
+	}
+
+	removed := make([]bool, n)
+	remaining := newBlossom(adjacency, removed).maximumMatching()
+	pairs := [][2]int{}
+	for _, edge := range candidates {
+		if remaining == 0 {
+			break
+		}
+		if edge.A == edge.B || removed[edge.A] || removed[edge.B] {
+			continue
+		}
+
+		removed[edge.A], removed[edge.B] = true, true
+		if left := newBlossom(adjacency, removed).maximumMatching(); left == remaining-1 {
+			pairs = append(pairs, [2]int{edge.A, edge.B})
+			remaining = left
+		} else {
+			removed[edge.A], removed[edge.B] = false, false
+		}
+	}
+	return pairs
+}
+
+// blossom finds maximum matchings of a general graph with Edmonds' blossom
+// algorithm, ignoring the removed vertices
+type blossom struct {
+	adjacency [][]int
+	removed   []bool
+
+	// match is the vertex matched with each vertex, -1 if none
+	match []int
+
+	// parent, base and used are the state of the search of an augmenting path
+	parent []int
+	base   []int
+	used   []bool
+}
+
+func newBlossom(adjacency [][]int, removed []bool) *blossom {
+	n := len(adjacency)
+	b := &blossom{
+		adjacency: adjacency,
+		removed:   removed,
+		match:     make([]int, n),
+		parent:    make([]int, n),
+		base:      make([]int, n),
+		used:      make([]bool, n),
+	}
+	for i := range b.match {
+		b.match[i] = -1
+	}
+	return b
+}
+
+// maximumMatching matches as many vertices as possible and returns the
+// number of pairs
+func (b *blossom) maximumMatching() int {
+	pairs := 0
+	for root := range b.adjacency {
+		if b.removed[root] || b.match[root] != -1 {
+			continue
+		}
+		for v := b.findPath(root); v != -1; {
+			parent := b.parent[v]
+			next := b.match[parent]
+			b.match[v], b.match[parent] = parent, v
+			v = next
+		}
+		if b.match[root] != -1 {
+			pairs++
+		}
+	}
+	return pairs
+}
+
+// findPath searches an augmenting path from the root, and returns the free
+// vertex ending it, -1 if there is none
+func (b *blossom) findPath(root int) int {
+	for i := range b.adjacency {
+		b.used[i] = false
+		b.parent[i] = -1
+		b.base[i] = i
+	}
+	b.used[root] = true
+	queue := []int{root}
+
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, to := range b.adjacency[v] {
+			if b.removed[to] || b.base[v] == b.base[to] || b.match[v] == to {
+				continue
+			}
+			if to == root || (b.match[to] != -1 && b.parent[b.match[to]] != -1) {
+				// odd cycle: contract the blossom into its base
+				currentBase := b.lowestCommonAncestor(v, to)
+				inBlossom := make([]bool, len(b.adjacency))
+				b.markPath(v, currentBase, to, inBlossom)
+				b.markPath(to, currentBase, v, inBlossom)
+				for i := range b.adjacency {
+					if inBlossom[b.base[i]] {
+						b.base[i] = currentBase
+						if !b.used[i] {
+							b.used[i] = true
+							queue = append(queue, i)
+						}
+					}
+				}
+			} else if b.parent[to] == -1 {
+				b.parent[to] = v
+				if b.match[to] == -1 {
+					return to
+				}
+				b.used[b.match[to]] = true
+				queue = append(queue, b.match[to])
+			}
+		}
+	}
+	return -1
+}
+
+// lowestCommonAncestor returns the base of the blossom closing the alternating
+// paths from the root to a and c
+func (b *blossom) lowestCommonAncestor(a, c int) int {
+	visited := make([]bool, len(b.adjacency))
+	for {
+		a = b.base[a]
+		visited[a] = true
+		if b.match[a] == -1 {
+			break
+		}
+		a = b.parent[b.match[a]]
+	}
+	for {
+		c = b.base[c]
+		if visited[c] {
+			return c
+		}
+		c = b.parent[b.match[c]]
+	}
+}
+
+// markPath marks the blossom vertices on the path from v to its base, and
+// links them to make the blossom traversable both ways
+func (b *blossom) markPath(v, currentBase, child int, inBlossom []bool) {
+	for b.base[v] != currentBase {
+		inBlossom[b.base[v]] = true
+		inBlossom[b.base[b.match[v]]] = true
+		b.parent[v] = child
+		child = b.match[v]
+		v = b.parent[b.match[v]]
+	}
+}
@@ -0,0 +1,91 @@
+package solver
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// bruteForceMatching returns the size of a maximum matching of the first
+// free vertex onwards, trying every edge
+func bruteForceMatching(n int, adjacent [][]bool, used []bool) int {
+	first := -1
+	for v := 0; v < n; v++ {
+		if !used[v] {
+			first = v
+			break
+		}
+	}
+	if first == -1 {
+		return 0
+	}
+
+	used[first] = true
+	best := bruteForceMatching(n, adjacent, used)
+	for other := first + 1; other < n; other++ {
+		if !used[other] && adjacent[first][other] {
+			used[other] = true
+			best = max(best, 1+bruteForceMatching(n, adjacent, used))
+			used[other] = false
+		}
+	}
+	used[first] = false
+	return best
+}
+
+func TestMaximumMatchingCardinality(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for iteration := 0; iteration < 200; iteration++ {
+		n := 2 + r.Intn(9)
+		density := r.Float64()
+		adjacent := make([][]bool, n)
+		for i := range adjacent {
+			adjacent[i] = make([]bool, n)
+		}
+		edges := []MatchingEdge{}
+		for a := 0; a < n; a++ {
+			for b := a + 1; b < n; b++ {
+				if r.Float64() < density {
+					adjacent[a][b], adjacent[b][a] = true, true
+					edges = append(edges, MatchingEdge{A: a, B: b, Penalty: float64(r.Intn(3))})
+				}
+			}
+		}
+
+		pairs := MaximumMatching(n, edges, r)
+		if want := bruteForceMatching(n, adjacent, make([]bool, n)); len(pairs) != want {
+			t.Fatalf("MaximumMatching() of %v returned %d pairs, want %d", edges, len(pairs), want)
+		}
+		matched := map[int]bool{}
+		for _, pair := range pairs {
+			if !adjacent[pair[0]][pair[1]] || matched[pair[0]] || matched[pair[1]] {
+				t.Fatalf("MaximumMatching() of %v returned invalid pairs %v", edges, pairs)
+			}
+			matched[pair[0]], matched[pair[1]] = true, true
+		}
+	}
+}
+
+func TestMaximumMatchingPenalties(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	// First fit on the cheapest edge would leave 0 and 3 unpaired
+	path := []MatchingEdge{{A: 0, B: 1, Penalty: 1}, {A: 1, B: 2, Penalty: 0}, {A: 2, B: 3, Penalty: 1}}
+	if pairs := MaximumMatching(4, path, r); len(pairs) != 2 || pairs[0][0]+pairs[0][1] == 3 {
+		t.Errorf("MaximumMatching() of a path = %v, want 0 & 1 and 2 & 3", pairs)
+	}
+
+	// Both matchings of a square are maximum, the cheapest one is picked
+	square := []MatchingEdge{{A: 0, B: 1, Penalty: 1}, {A: 1, B: 2}, {A: 2, B: 3, Penalty: 1}, {A: 3, B: 0}}
+	for i := 0; i < 10; i++ {
+		pairs := MaximumMatching(4, square, r)
+		if len(pairs) != 2 || pairs[0][0]+pairs[0][1] != 3 || pairs[1][0]+pairs[1][1] != 3 {
+			t.Fatalf("MaximumMatching() of a square = %v, want 1 & 2 and 3 & 0", pairs)
+		}
+	}
+
+	// A blossom: the triangle 0, 1, 2 with a tail on each side
+	blossom := []MatchingEdge{{A: 0, B: 1}, {A: 1, B: 2}, {A: 2, B: 0}, {A: 0, B: 3}, {A: 2, B: 4}, {A: 1, B: 5}}
+	if pairs := MaximumMatching(6, blossom, r); len(pairs) != 3 {
+		t.Errorf("MaximumMatching() of a blossom returned %v, want 3 pairs", pairs)
+	}
+}
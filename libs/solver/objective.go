@@ -0,0 +1,139 @@
+package solver
+
+import (
+	"matchmaker/libs/types"
+	"time"
+)
+
+// ObjectiveWeights weighs the components of the score minimized by the search.
+// Zero weights only minimize missing coverage.
+type ObjectiveWeights struct {
+	// Coverage weighs the missing coverage of work ranges, counted in coverage periods
+	Coverage float64
+
+	// Fairness weighs the variance of the number of sessions per person, so
+	// that sessions are spread over everyone rather than packed on a few people
+	Fairness float64
+
+	// ReviewerLoad weighs the sessions booked for good reviewers, people at
+	// the good reviewer level or above, each counted
+	// relative to the reviewer's weekly maximum, so that good reviewers are not
+	// paired together when a less experienced reviewer is available
+	ReviewerLoad float64
+
+	// Repetition weighs the sessions of squads paired recently, each counted
+	// by its repeat penalty
+	Repetition float64
+
+	// Team weighs the sessions of squads staying within teams, each counted
+	// by its team penalty
+	Team float64
+}
+
+// Objective contains the components of the score of a planning. Lower scores
+// are better.
+type Objective struct {
+	MissingCoverage int
+	MaxCoverage     int
+	Fairness        float64
+	ReviewerLoad    float64
+	Repetition      float64
+	Team            float64
+
+	// Constraints sums the weighted violations of soft constraints
+	Constraints float64
+
+	Score float64
+}
+
+// resolveWeights returns the weights to use, falling back to coverage only
+// when none is set
+func resolveWeights(weights ObjectiveWeights) ObjectiveWeights {
+	if weights == (ObjectiveWeights{}) {
+		return ObjectiveWeights{Coverage: 1}
+	}
+	return weights
+}
+
+// objective scores plannings of a problem
+type objective struct {
+	problem     *types.Problem
+	weights     ObjectiveWeights
+	periodSpan  time.Duration
+	constraints Constraints
+	seniority   types.Seniority
+}
+
+func newObjective(problem *types.Problem, options Options) *objective {
+	return &objective{
+		problem:     problem,
+		weights:     resolveWeights(options.Weights),
+		periodSpan:  options.Parameters.CoveragePeriodSpan,
+		constraints: resolveConstraints(options.Constraints),
+		seniority:   resolveSeniority(options.Seniority).Seniority,
+	}
+}
+
+// newState returns an empty planning of the candidate sessions, following the
+// constraints of the objective and keeping the totals it scores
+func (o *objective) newState(candidates []*types.ReviewSession) *searchState {
+	return newPeopleIndex(candidates, o.constraints, o).empty()
+}
+
+// evaluate computes every component of the score of the sessions
+func (o *objective) evaluate(sessions []*types.ReviewSession) Objective {
+	return o.evaluateState(o.newState(sessions).withAll(sessions))
+}
+
+// evaluateState computes every component of the score of a planning from
+// the totals kept by the state, whatever its number of sessions
+func (o *objective) evaluateState(state *searchState) Objective {
+	result := Objective{
+		MissingCoverage: state.missingCoverage,
+		MaxCoverage:     state.maxCoverage,
+		Fairness:        sessionCountVariance(len(o.problem.People), state.sessionCounts, state.squaredCounts),
+		ReviewerLoad:    state.reviewerLoad,
+		Repetition:      state.repetition,
+		Team:            state.team,
+		Constraints:     state.penalty,
+	}
+
+	result.Score = o.weights.Coverage*float64(result.MissingCoverage) +
+		o.weights.Fairness*result.Fairness +
+		o.weights.ReviewerLoad*result.ReviewerLoad +
+		o.weights.Repetition*result.Repetition +
+		o.weights.Team*result.Team +
+		result.Constraints
+	return result
+}
+
+// score returns the weighted score of a planning and its max coverage
+func (o *objective) score(state *searchState) (float64, int) {
+	result := o.evaluateState(state)
+	return result.Score, result.MaxCoverage
+}
+
+// coverageScore returns the part of the score due to the given missing
+// coverage. Other components are never negative, so it is a lower bound of
+// the score of any planning with this missing coverage.
+func (o *objective) coverageScore(missingCoverage int) float64 {
+	return o.weights.Coverage * float64(missingCoverage)
+}
+
+// sessionCountVariance returns the variance of the number of sessions of the
+// people, from the sum of their numbers of sessions and of their squares
+func sessionCountVariance(people int, sessionCounts int, squaredCounts int) float64 {
+	if people == 0 {
+		return 0
+	}
+	return float64(people*squaredCounts-sessionCounts*sessionCounts) / float64(people*people)
+}
+
+// reviewerLoad returns the load a session adds to a person: the session
+// relative to their weekly maximum for good reviewers, 0 for other people
+func reviewerLoad(person *types.Person, seniority types.Seniority) float64 {
+	if !seniority.IsGoodReviewer(person) || person.MaxSessionsPerWeek <= 0 {
+		return 0
+	}
+	return 1 / float64(person.MaxSessionsPerWeek)
+}
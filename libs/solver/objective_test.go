@@ -0,0 +1,121 @@
+package solver
+
+import (
+	"context"
+	"matchmaker/libs/testutils"
+	"matchmaker/libs/types"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSessionCountVariance(t *testing.T) {
+	if got := sessionCountVariance(0, 0, 0); got != 0 {
+		t.Errorf("sessionCountVariance() without people = %v, want 0", got)
+	}
+
+	// Evenly spread sessions have no variance
+	if got := sessionCountVariance(4, 4, 4); got != 0 {
+		t.Errorf("sessionCountVariance() with even counts = %v, want 0", got)
+	}
+
+	// Counts 2, 2, 0, 0 have a mean of 1 and a variance of 1
+	if got := sessionCountVariance(4, 4, 8); got != 1 {
+		t.Errorf("sessionCountVariance() with packed counts = %v, want 1", got)
+	}
+}
+
+func TestReviewerLoad(t *testing.T) {
+	good1 := &types.Person{Email: "good1@example.com", IsGoodReviewer: true, MaxSessionsPerWeek: 2}
+	good2 := &types.Person{Email: "good2@example.com", IsGoodReviewer: true, MaxSessionsPerWeek: 4}
+	other := &types.Person{Email: "other@example.com", MaxSessionsPerWeek: 2}
+
+	for _, test := range []struct {
+		person *types.Person
+		want   float64
+	}{
+		{good1, 0.5},
+		{good2, 0.25},
+		{other, 0},
+	} {
+		if got := reviewerLoad(test.person, types.DefaultSeniority); got != test.want {
+			t.Errorf("reviewerLoad(%s) = %v, want %v", test.person.Email, got, test.want)
+		}
+	}
+}
+
+func TestObjectiveEvaluate(t *testing.T) {
+	start := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	good1 := &types.Person{Email: "good1@example.com", IsGoodReviewer: true, MaxSessionsPerWeek: 2}
+	good2 := &types.Person{Email: "good2@example.com", IsGoodReviewer: true, MaxSessionsPerWeek: 2}
+	other := &types.Person{Email: "other@example.com", MaxSessionsPerWeek: 2}
+	problem := &types.Problem{
+		People:           []*types.Person{good1, good2, other},
+		WorkRanges:       []*types.Range{{Start: start, End: start.Add(2 * time.Hour)}},
+		TargetCoverage:   1,
+		MaxTotalCoverage: 2,
+	}
+	session := &types.ReviewSession{
+		Reviewers: &types.Squad{People: []*types.Person{good1, good2}, TeamPenalty: 1},
+		Range:     &types.Range{Start: start, End: start.Add(time.Hour)},
+	}
+
+	objective := newObjective(problem, Options{
+		Parameters: SearchParameters{CoveragePeriodSpan: defaultCoveragePeriodSpan},
+		Weights:    ObjectiveWeights{Coverage: 1, Fairness: 3, ReviewerLoad: 2, Team: 0.5},
+	})
+	result := objective.evaluate([]*types.ReviewSession{session})
+
+	if result.MissingCoverage != 2 {
+		t.Errorf("evaluate() missing coverage = %d, want 2", result.MissingCoverage)
+	}
+	if result.MaxCoverage != 1 {
+		t.Errorf("evaluate() max coverage = %d, want 1", result.MaxCoverage)
+	}
+	// Counts 1, 1, 0 have a variance of 2/9
+	if math.Abs(result.Fairness-2.0/9) > 1e-9 {
+		t.Errorf("evaluate() fairness = %v, want %v", result.Fairness, 2.0/9)
+	}
+	if result.ReviewerLoad != 1 {
+		t.Errorf("evaluate() reviewer load = %v, want 1", result.ReviewerLoad)
+	}
+	if result.Team != 1 {
+		t.Errorf("evaluate() team = %v, want 1", result.Team)
+	}
+	if want := 2 + 3*2.0/9 + 2*1 + 0.5*1; math.Abs(result.Score-want) > 1e-9 {
+		t.Errorf("evaluate() score = %v, want %v", result.Score, want)
+	}
+
+	// Without weights, the score is the missing coverage
+	objective = newObjective(problem, Options{Parameters: SearchParameters{CoveragePeriodSpan: defaultCoveragePeriodSpan}})
+	if score := objective.evaluate([]*types.ReviewSession{session}).Score; score != 2 {
+		t.Errorf("score() without weights = %v, want 2", score)
+	}
+}
+
+func TestSolveObjective(t *testing.T) {
+	configMock := testutils.NewConfigMock()
+	configMock.SetupWorkHours()
+	defer configMock.Restore()
+
+	weights := ObjectiveWeights{Coverage: 1, Fairness: 1, ReviewerLoad: 0.5}
+	for _, name := range Algorithms() {
+		t.Run(name, func(t *testing.T) {
+			_, stats, err := Solve(context.Background(), newTestProblem(), Options{Algorithm: name, Weights: weights})
+			if err != nil {
+				t.Fatalf("Solve() returned error: %v", err)
+			}
+
+			if stats.Weights != weights {
+				t.Errorf("Solve() stats weights = %+v, want %+v", stats.Weights, weights)
+			}
+			want := float64(stats.Objective.MissingCoverage) + stats.Objective.Fairness + 0.5*stats.Objective.ReviewerLoad
+			if math.Abs(stats.Objective.Score-want) > 1e-9 {
+				t.Errorf("Solve() score = %v, want %v", stats.Objective.Score, want)
+			}
+			if stats.MissingCoverage != stats.Objective.MissingCoverage {
+				t.Errorf("Solve() missing coverage %d differs from objective %d", stats.MissingCoverage, stats.Objective.MissingCoverage)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package solver
+
+import (
+	"matchmaker/libs/config"
+	"matchmaker/libs/types"
+	"time"
+)
+
+// defaultCoveragePeriodSpan is the granularity at which coverage is measured
+// when the session duration allows it
+const defaultCoveragePeriodSpan = 30 * time.Minute
+
+// SearchParameters tunes the search behavior of the matching algorithm. The
+// beam search is a form of "limited breadth-first search" balancing between
+// finding good solutions and keeping computation time reasonable.
+// Zero values are picked automatically from the size of the problem.
+type SearchParameters struct {
+	// MaxWidthExploration limits the "width" of the search tree during exploration.
+	// It controls how many alternative solutions the algorithm will explore at each decision point.
+	// When the algorithm finds multiple possible matches, it will only explore up to this many options.
+	//
+	// Recommendations:
+	// - For small groups (10-20 people): 2-3 is sufficient
+	// - For medium groups (20-50 people): 3-5 provides better results
+	// - For large groups (50+ people): 5-10 may be needed for optimal results
+	// - Higher values improve solution quality but increase computation time exponentially
+	MaxWidthExploration int
+
+	// MaxExplorationPathLength limits the "depth" of the search tree during exploration.
+	// It controls how many sequential decisions the algorithm will make before stopping a particular path.
+	// The algorithm stops exploring any path that exceeds this length after the first decision.
+	//
+	// Recommendations:
+	// - For weekly planning: 5-10 is typically sufficient
+	// - For monthly planning: 10-15 may be needed
+	// - For quarterly planning: 15-20 could be beneficial
+	// - Higher values allow for more complex solution paths but increase computation time
+	MaxExplorationPathLength int
+
+	// CoveragePeriodSpan is the granularity at which the coverage of work ranges is measured.
+	// Smaller periods measure coverage more precisely but make every evaluation slower.
+	CoveragePeriodSpan time.Duration
+}
+
+// resolveParameters fills the zero parameters with values picked from the
+// number of people and the period spanned by the work ranges of the problem,
+// following the recommendations documented on SearchParameters
+func resolveParameters(problem *types.Problem, parameters SearchParameters) SearchParameters {
+	if parameters.MaxWidthExploration <= 0 {
+		parameters.MaxWidthExploration = autoMaxWidthExploration(len(problem.People))
+	}
+	if parameters.MaxExplorationPathLength <= 0 {
+		parameters.MaxExplorationPathLength = autoMaxExplorationPathLength(problem.WorkRanges)
+	}
+	if parameters.CoveragePeriodSpan <= 0 {
+		parameters.CoveragePeriodSpan = autoCoveragePeriodSpan(config.GetSessionDuration())
+	}
+	return parameters
+}
+
+// autoMaxWidthExploration picks a beam width from the group size
+func autoMaxWidthExploration(peopleCount int) int {
+	switch {
+	case peopleCount < 20:
+		return 3
+	case peopleCount < 50:
+		return 4
+	default:
+		return 5
+	}
+}
+
+// autoMaxExplorationPathLength picks a search depth from the number of weeks
+// spanned by the work ranges
+func autoMaxExplorationPathLength(workRanges []*types.Range) int {
+	if len(workRanges) == 0 {
+		return 10
+	}
+
+	first := workRanges[0].Start
+	last := workRanges[0].End
+	for _, workRange := range workRanges {
+		if workRange.Start.Before(first) {
+			first = workRange.Start
+		}
+		if workRange.End.After(last) {
+			last = workRange.End
+		}
+	}
+
+	weeks := int(last.Sub(first).Hours()/(7*24)) + 1
+	switch {
+	case weeks <= 1:
+		return 10
+	case weeks <= 5:
+		return 15
+	default:
+		return 20
+	}
+}
+
+// autoCoveragePeriodSpan measures coverage every 30 minutes, or at the
+// session duration if sessions are shorter
+func autoCoveragePeriodSpan(sessionDuration time.Duration) time.Duration {
+	if sessionDuration > 0 && sessionDuration < defaultCoveragePeriodSpan {
+		return sessionDuration
+	}
+	return defaultCoveragePeriodSpan
+}
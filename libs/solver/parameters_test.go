@@ -0,0 +1,128 @@
+package solver
+
+import (
+	"context"
+	"matchmaker/libs/testutils"
+	"matchmaker/libs/types"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestResolveParameters(t *testing.T) {
+	configMock := testutils.NewConfigMock()
+	configMock.SetupWorkHours()
+	defer configMock.Restore()
+
+	problem := newTestProblem()
+
+	// Explicit parameters are kept
+	explicit := SearchParameters{
+		MaxWidthExploration:      7,
+		MaxExplorationPathLength: 12,
+		CoveragePeriodSpan:       15 * time.Minute,
+	}
+	if got := resolveParameters(problem, explicit); got != explicit {
+		t.Errorf("resolveParameters() = %+v, want %+v", got, explicit)
+	}
+
+	// Zero parameters are picked from the problem
+	got := resolveParameters(problem, SearchParameters{})
+	want := SearchParameters{
+		MaxWidthExploration:      3,
+		MaxExplorationPathLength: 10,
+		CoveragePeriodSpan:       30 * time.Minute,
+	}
+	if got != want {
+		t.Errorf("resolveParameters() = %+v, want %+v", got, want)
+	}
+}
+
+func TestAutoMaxWidthExploration(t *testing.T) {
+	tests := []struct {
+		peopleCount int
+		want        int
+	}{
+		{peopleCount: 5, want: 3},
+		{peopleCount: 19, want: 3},
+		{peopleCount: 20, want: 4},
+		{peopleCount: 49, want: 4},
+		{peopleCount: 120, want: 5},
+	}
+
+	for _, tt := range tests {
+		if got := autoMaxWidthExploration(tt.peopleCount); got != tt.want {
+			t.Errorf("autoMaxWidthExploration(%d) = %d, want %d", tt.peopleCount, got, tt.want)
+		}
+	}
+}
+
+func TestAutoMaxExplorationPathLength(t *testing.T) {
+	start := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	workRangesFor := func(days int) []*types.Range {
+		ranges := []*types.Range{}
+		for day := 0; day < days; day++ {
+			dayStart := start.AddDate(0, 0, day)
+			ranges = append(ranges, &types.Range{Start: dayStart, End: dayStart.Add(2 * time.Hour)})
+		}
+		return ranges
+	}
+
+	tests := []struct {
+		name       string
+		workRanges []*types.Range
+		want       int
+	}{
+		{name: "no work ranges", workRanges: []*types.Range{}, want: 10},
+		{name: "one week", workRanges: workRangesFor(5), want: 10},
+		{name: "one month", workRanges: workRangesFor(30), want: 15},
+		{name: "one quarter", workRanges: workRangesFor(90), want: 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := autoMaxExplorationPathLength(tt.workRanges); got != tt.want {
+				t.Errorf("autoMaxExplorationPathLength() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAutoCoveragePeriodSpan(t *testing.T) {
+	if got := autoCoveragePeriodSpan(time.Hour); got != 30*time.Minute {
+		t.Errorf("autoCoveragePeriodSpan(1h) = %s, want 30m", got)
+	}
+	if got := autoCoveragePeriodSpan(20 * time.Minute); got != 20*time.Minute {
+		t.Errorf("autoCoveragePeriodSpan(20m) = %s, want 20m", got)
+	}
+	if got := autoCoveragePeriodSpan(0); got != 30*time.Minute {
+		t.Errorf("autoCoveragePeriodSpan(0) = %s, want 30m", got)
+	}
+}
+
+func TestSolveWithParameters(t *testing.T) {
+	configMock := testutils.NewConfigMock()
+	configMock.SetupWorkHours()
+	defer configMock.Restore()
+
+	viper.Set("sessions.sessionDurationMinutes", 60)
+	_, stats, err := Solve(context.Background(), newTestProblem(), Options{
+		Parameters: SearchParameters{MaxWidthExploration: 1, CoveragePeriodSpan: 15 * time.Minute},
+	})
+	if err != nil {
+		t.Fatalf("Solve() returned error: %v", err)
+	}
+
+	if stats.Parameters.MaxWidthExploration != 1 || stats.Parameters.CoveragePeriodSpan != 15*time.Minute {
+		t.Errorf("Solve() used parameters %+v, want max width 1 and coverage period 15m", stats.Parameters)
+	}
+	if stats.Parameters.MaxExplorationPathLength != 10 {
+		t.Errorf("Solve() used max depth %d, want the automatic value 10", stats.Parameters.MaxExplorationPathLength)
+	}
+
+	// The work range spans 3 hours, i.e. 12 periods of 15 minutes
+	if stats.WorstMissingCoverage != 12 {
+		t.Errorf("Solve() worst missing coverage = %d, want 12", stats.WorstMissingCoverage)
+	}
+}
@@ -0,0 +1,78 @@
+package solver
+
+const (
+	// persistentBits is the number of bits of an index picked at each level
+	// of a persistent array
+	persistentBits = 4
+
+	// persistentWidth is the number of children of a node of a persistent array
+	persistentWidth = 1 << persistentBits
+	persistentMask  = persistentWidth - 1
+)
+
+// persistentArray is an immutable array of fixed length, stored as a tree of
+// small nodes. Setting an element returns a new array sharing every node but
+// the ones on the path to the element, so it costs O(log n) whatever the
+// length of the array. Missing nodes hold zero values.
+type persistentArray[T any] struct {
+	root *persistentNode[T]
+
+	// depth is the number of levels of inner nodes above the leaves
+	depth int
+}
+
+// persistentNode is an inner node of a persistent array, with children, or a
+// leaf, with values
+type persistentNode[T any] struct {
+	children []*persistentNode[T]
+	values   []T
+}
+
+// newPersistentArray returns an array of the given length filled with zero values
+func newPersistentArray[T any](length int) persistentArray[T] {
+	depth := 0
+	for capacity := persistentWidth; capacity < length; capacity *= persistentWidth {
+		depth++
+	}
+	return persistentArray[T]{depth: depth}
+}
+
+// get returns the element at the given index
+func (a persistentArray[T]) get(index int) T {
+	node := a.root
+	for level := a.depth; level > 0 && node != nil; level-- {
+		node = node.children[(index>>(level*persistentBits))&persistentMask]
+	}
+	if node == nil {
+		var zero T
+		return zero
+	}
+	return node.values[index&persistentMask]
+}
+
+// set returns a new array with the element at the given index replaced
+func (a persistentArray[T]) set(index int, value T) persistentArray[T] {
+	return persistentArray[T]{root: a.root.set(a.depth, index, value), depth: a.depth}
+}
+
+// set returns a copy of the node, which may be nil, with the element at the
+// given index replaced
+func (n *persistentNode[T]) set(level int, index int, value T) *persistentNode[T] {
+	updated := &persistentNode[T]{}
+	if level == 0 {
+		updated.values = make([]T, persistentWidth)
+		if n != nil {
+			copy(updated.values, n.values)
+		}
+		updated.values[index&persistentMask] = value
+		return updated
+	}
+
+	updated.children = make([]*persistentNode[T], persistentWidth)
+	if n != nil {
+		copy(updated.children, n.children)
+	}
+	child := (index >> (level * persistentBits)) & persistentMask
+	updated.children[child] = updated.children[child].set(level-1, index, value)
+	return updated
+}
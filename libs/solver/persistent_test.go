@@ -0,0 +1,36 @@
+package solver
+
+import "testing"
+
+func TestPersistentArray(t *testing.T) {
+	for _, length := range []int{1, persistentWidth, persistentWidth + 1, 1000} {
+		empty := newPersistentArray[int](length)
+		array := empty
+		for i := 0; i < length; i += 3 {
+			array = array.set(i, i+1)
+		}
+		updated := array.set(length-1, -1)
+
+		for i := 0; i < length; i++ {
+			if got := empty.get(i); got != 0 {
+				t.Errorf("length %d: empty get(%d) = %d, want 0", length, i, got)
+			}
+
+			want := 0
+			if i%3 == 0 {
+				want = i + 1
+			}
+			if got := array.get(i); got != want {
+				t.Errorf("length %d: get(%d) = %d, want %d", length, i, got, want)
+			}
+
+			// Setting an element leaves the previous array untouched
+			if i == length-1 {
+				want = -1
+			}
+			if got := updated.get(i); got != want {
+				t.Errorf("length %d: updated get(%d) = %d, want %d", length, i, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package solver
+
+import (
+	"sync"
+	"time"
+)
+
+// progressInterval is the minimum delay between two progress reports
+const progressInterval = 200 * time.Millisecond
+
+// Progress describes the state of a running search
+type Progress struct {
+	Iterations int64
+	BestScore  float64
+	Depth      int
+	Elapsed    time.Duration
+}
+
+// ProgressFunc receives progress updates from a running search
+type ProgressFunc func(Progress)
+
+// progressReporter forwards the progress of a search to a callback, at most
+// once per progressInterval. A nil reporter discards every report.
+type progressReporter struct {
+	callback   ProgressFunc
+	start      time.Time
+	mutex      sync.Mutex
+	lastReport time.Time
+}
+
+// newProgressReporter returns a reporter for the callback, or nil if there is none
+func newProgressReporter(callback ProgressFunc) *progressReporter {
+	if callback == nil {
+		return nil
+	}
+	return &progressReporter{
+		callback: callback,
+		start:    time.Now(),
+	}
+}
+
+// report sends the progress to the callback unless a report was sent recently
+func (r *progressReporter) report(iterations int64, bestScore float64, depth int) {
+	if r == nil {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+	if now.Sub(r.lastReport) < progressInterval {
+		return
+	}
+	r.lastReport = now
+
+	r.callback(Progress{
+		Iterations: iterations,
+		BestScore:  bestScore,
+		Depth:      depth,
+		Elapsed:    now.Sub(r.start),
+	})
+}
@@ -0,0 +1,40 @@
+package solver
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProgressReporter(t *testing.T) {
+	// A nil reporter discards reports
+	var nilReporter *progressReporter
+	nilReporter.report(1, 2, 3)
+
+	if newProgressReporter(nil) != nil {
+		t.Error("newProgressReporter(nil) returned a reporter, want nil")
+	}
+
+	reports := []Progress{}
+	reporter := newProgressReporter(func(progress Progress) {
+		reports = append(reports, progress)
+	})
+
+	reporter.report(1, 10, 1)
+	reporter.report(2, 8, 2)
+	if len(reports) != 1 {
+		t.Fatalf("reporter sent %d reports, want 1 (second one throttled)", len(reports))
+	}
+	if reports[0].Iterations != 1 || reports[0].BestScore != 10 || reports[0].Depth != 1 {
+		t.Errorf("reporter sent %+v, want iterations 1, best score 10 and depth 1", reports[0])
+	}
+
+	// Reports are sent again once the interval has elapsed
+	reporter.lastReport = time.Now().Add(-progressInterval)
+	reporter.report(3, 6, 3)
+	if len(reports) != 2 {
+		t.Fatalf("reporter sent %d reports, want 2", len(reports))
+	}
+	if reports[1].Iterations != 3 || reports[1].Elapsed < 0 {
+		t.Errorf("reporter sent %+v, want iterations 3 and a positive elapsed time", reports[1])
+	}
+}
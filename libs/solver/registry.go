@@ -0,0 +1,126 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"matchmaker/libs/types"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultAlgorithm is the strategy used when none is specified
+const DefaultAlgorithm = "beam"
+
+// Solver is a search strategy that builds a planning for a problem.
+// Strategies stop searching when the context is done and return the best
+// planning found so far.
+type Solver interface {
+	Solve(ctx context.Context, problem *types.Problem, options Options) (*Solution, *Stats)
+}
+
+// Options configures a solver run
+type Options struct {
+	// Algorithm is the name of the strategy to run, DefaultAlgorithm if empty
+	Algorithm string
+
+	// TimeLimit stops the search after the given duration when positive
+	TimeLimit time.Duration
+
+	// Progress is called periodically with the state of the search when set
+	Progress ProgressFunc
+
+	// Parameters tunes the search, zero values are picked from the problem size
+	Parameters SearchParameters
+
+	// Seed initializes the random generator of the search, so that a problem
+	// solved twice with the same seed yields the same planning
+	Seed int64
+
+	// Weights balances the components of the score minimized by the search
+	Weights ObjectiveWeights
+
+	// Repeats penalizes or forbids squads of people paired recently
+	Repeats RepeatPolicy
+
+	// Teams penalizes or forbids squads according to the teams of their people
+	Teams TeamPolicy
+
+	// Seniority sets the rules squads follow on the levels of their people,
+	// DefaultSeniorityRules if it has no levels
+	Seniority SeniorityRules
+
+	// SquadSize is the number of people attending each session, pairs if zero
+	SquadSize int
+
+	// Constraints are the rules sessions follow, every registered constraint
+	// as a hard rule if nil
+	Constraints Constraints
+}
+
+// Stats contains information about a solver run
+type Stats struct {
+	Algorithm            string
+	Iterations           int64
+	MissingCoverage      int
+	WorstMissingCoverage int
+	MaxCoverage          int
+	Duration             time.Duration
+
+	// Optimal is true when the strategy proved that no planning has a lower
+	// score
+	Optimal bool
+
+	// Interrupted is true when the search was stopped by a cancellation or
+	// its time limit before completing
+	Interrupted bool
+
+	// Parameters are the search parameters used, after automatic resolution
+	Parameters SearchParameters
+
+	// Objective details the score of the returned planning
+	Objective Objective
+
+	// Weights are the objective weights used
+	Weights ObjectiveWeights
+
+	// Constraints are the rules followed by the planning
+	Constraints Constraints
+
+	// Seniority are the rules followed by the squads on the levels of their people
+	Seniority SeniorityRules
+
+	// BusiestDay is the highest number of sessions of a person on a single day
+	BusiestDay int
+}
+
+// registry maps strategy names to their constructors
+var registry = map[string]func() Solver{}
+
+// Register makes a solver strategy available under the given name.
+// It panics if a strategy with the same name is already registered.
+func Register(name string, factory func() Solver) {
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("solver strategy %q is already registered", name))
+	}
+	registry[name] = factory
+}
+
+// Get returns a new instance of the named solver strategy
+func Get(name string) (Solver, error) {
+	factory, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown algorithm %q (available: %s)", name, strings.Join(Algorithms(), ", "))
+	}
+	return factory(), nil
+}
+
+// Algorithms returns the sorted names of all registered strategies
+func Algorithms() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
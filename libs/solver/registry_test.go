@@ -0,0 +1,160 @@
+package solver
+
+import (
+	"context"
+	"matchmaker/libs/testutils"
+	"matchmaker/libs/types"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newTestProblem creates a small problem with three people over one morning
+func newTestProblem() *types.Problem {
+	person1 := &types.Person{Email: "person1@example.com", IsGoodReviewer: true, MaxSessionsPerWeek: 2}
+	person2 := &types.Person{Email: "person2@example.com", IsGoodReviewer: true, MaxSessionsPerWeek: 2}
+	person3 := &types.Person{Email: "person3@example.com", MaxSessionsPerWeek: 2}
+
+	start := time.Date(2024, 4, 2, 9, 0, 0, 0, time.UTC)
+	return &types.Problem{
+		People: []*types.Person{person1, person2, person3},
+		WorkRanges: []*types.Range{
+			{Start: start, End: start.Add(3 * time.Hour)},
+		},
+		TargetCoverage:   1,
+		MaxTotalCoverage: 2,
+	}
+}
+
+func TestGet(t *testing.T) {
+	for _, name := range []string{"beam", "greedy", "exact", "anneal"} {
+		strategy, err := Get(name)
+		if err != nil {
+			t.Errorf("Get(%q) returned error: %v", name, err)
+		}
+		if strategy == nil {
+			t.Errorf("Get(%q) returned nil strategy", name)
+		}
+	}
+
+	if _, err := Get("unknown"); err == nil {
+		t.Error("Get() returned no error for unknown algorithm")
+	}
+}
+
+func TestAlgorithms(t *testing.T) {
+	names := Algorithms()
+	if len(names) < 4 {
+		t.Errorf("Algorithms() returned %d names, want at least 4", len(names))
+	}
+	for i := 1; i < len(names); i++ {
+		if names[i-1] > names[i] {
+			t.Errorf("Algorithms() is not sorted: %v", names)
+		}
+	}
+}
+
+func TestRegisterDuplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Register() did not panic for a duplicate name")
+		}
+	}()
+	Register("beam", func() Solver { return &beamSolver{} })
+}
+
+func TestStrategies(t *testing.T) {
+	configMock := testutils.NewConfigMock()
+	configMock.SetupWorkHours()
+	defer configMock.Restore()
+
+	for _, name := range Algorithms() {
+		t.Run(name, func(t *testing.T) {
+			problem := newTestProblem()
+
+			solution, stats, err := Solve(context.Background(), problem, Options{Algorithm: name})
+			if err != nil {
+				t.Fatalf("Solve() returned error: %v", err)
+			}
+
+			if len(solution.Sessions) == 0 {
+				t.Error("Solve() returned no sessions")
+			}
+			if stats.Algorithm != name {
+				t.Errorf("Solve() stats algorithm = %q, want %q", stats.Algorithm, name)
+			}
+			if stats.MissingCoverage > stats.WorstMissingCoverage {
+				t.Errorf("Solve() missing coverage %d is worse than worst case %d", stats.MissingCoverage, stats.WorstMissingCoverage)
+			}
+			if stats.MaxCoverage > problem.MaxTotalCoverage {
+				t.Errorf("Solve() max coverage %d exceeds max total coverage %d", stats.MaxCoverage, problem.MaxTotalCoverage)
+			}
+
+			for i := 0; i < len(solution.Sessions)-1; i++ {
+				for j := i + 1; j < len(solution.Sessions); j++ {
+					if !isCompatible(solution.Sessions[i], []*types.ReviewSession{solution.Sessions[j]}) {
+						t.Errorf("Sessions %d and %d are not compatible", i, j)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestStrategiesSeed(t *testing.T) {
+	configMock := testutils.NewConfigMock()
+	configMock.SetupWorkHours()
+	defer configMock.Restore()
+
+	describe := func(solution *Solution) []string {
+		sessions := []string{}
+		for _, session := range solution.Sessions {
+			sessions = append(sessions, session.Start().String()+" "+session.Reviewers.GetDisplayName())
+		}
+		return sessions
+	}
+
+	for _, name := range Algorithms() {
+		t.Run(name, func(t *testing.T) {
+			first, _, err := Solve(context.Background(), newTestProblem(), Options{Algorithm: name, Seed: 42})
+			if err != nil {
+				t.Fatalf("Solve() returned error: %v", err)
+			}
+			second, _, err := Solve(context.Background(), newTestProblem(), Options{Algorithm: name, Seed: 42})
+			if err != nil {
+				t.Fatalf("Solve() returned error: %v", err)
+			}
+
+			if first.Seed != 42 {
+				t.Errorf("Solve() solution seed = %d, want 42", first.Seed)
+			}
+			if !reflect.DeepEqual(describe(first), describe(second)) {
+				t.Errorf("Solve() with the same seed returned different plannings: %v and %v", describe(first), describe(second))
+			}
+		})
+	}
+}
+
+func TestStrategiesSquadSize(t *testing.T) {
+	configMock := testutils.NewConfigMock()
+	configMock.SetupWorkHours()
+	defer configMock.Restore()
+
+	for _, name := range Algorithms() {
+		t.Run(name, func(t *testing.T) {
+			solution, _, err := Solve(context.Background(), newTestProblem(), Options{Algorithm: name, SquadSize: 3})
+			if err != nil {
+				t.Fatalf("Solve() returned error: %v", err)
+			}
+
+			if len(solution.Sessions) == 0 {
+				t.Error("Solve() returned no sessions")
+			}
+			for _, session := range solution.Sessions {
+				if len(session.Reviewers.People) != 3 {
+					t.Errorf("Solve() returned a session of %d people, want 3", len(session.Reviewers.People))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package solver
+
+import (
+	"fmt"
+	"matchmaker/libs/types"
+	"strings"
+)
+
+// SeniorityRules are the rules squads follow on the seniority levels of their
+// people
+type SeniorityRules struct {
+	// Seniority ranks people, DefaultSeniority if it has no levels
+	Seniority types.Seniority
+
+	// MinLevel is the level at least one person of each squad has, no rule if empty
+	MinLevel string
+
+	// MaxLevelGap is the highest number of levels between the people of a
+	// squad, no rule if 0
+	MaxLevelGap int
+}
+
+// DefaultSeniorityRules returns the default levels, with at least one good
+// reviewer in each squad
+func DefaultSeniorityRules() SeniorityRules {
+	return SeniorityRules{
+		Seniority: types.DefaultSeniority,
+		MinLevel:  types.DefaultSeniority.GoodReviewerLevel,
+	}
+}
+
+// resolveSeniority returns the seniority rules to follow, falling back to
+// the default ones when no level is set
+func resolveSeniority(rules SeniorityRules) SeniorityRules {
+	if len(rules.Seniority.Levels) == 0 {
+		return DefaultSeniorityRules()
+	}
+	return rules
+}
+
+// minRank returns the rank of the minimum level, 0 if there is none
+func (r SeniorityRules) minRank() int {
+	if r.MinLevel == "" {
+		return 0
+	}
+	return max(r.Seniority.Rank(r.MinLevel), 0)
+}
+
+// allowsGap returns true if the levels of the people are close enough
+func (r SeniorityRules) allowsGap(people []*types.Person) bool {
+	if r.MaxLevelGap <= 0 || len(people) == 0 {
+		return true
+	}
+
+	lowest, highest := r.Seniority.PersonRank(people[0]), r.Seniority.PersonRank(people[0])
+	for _, person := range people[1:] {
+		rank := r.Seniority.PersonRank(person)
+		lowest, highest = min(lowest, rank), max(highest, rank)
+	}
+	return highest-lowest <= r.MaxLevelGap
+}
+
+// String describes the levels and the rules
+func (r SeniorityRules) String() string {
+	rules := []string{}
+	if r.MinLevel != "" {
+		rules = append(rules, fmt.Sprintf("at least one %s or above", r.MinLevel))
+	}
+	if r.MaxLevelGap > 0 {
+		rules = append(rules, fmt.Sprintf("max level gap %d", r.MaxLevelGap))
+	}
+	if len(rules) == 0 {
+		rules = append(rules, "no rule")
+	}
+	return fmt.Sprintf("%s (%s)", strings.Join(r.Seniority.Levels, " < "), strings.Join(rules, ", "))
+}
@@ -0,0 +1,113 @@
+package solver
+
+import (
+	"matchmaker/libs/config"
+	"matchmaker/libs/types"
+	"matchmaker/libs/util"
+)
+
+// skillMatching returns the configured skill matching, overlap if the
+// configuration is invalid
+func skillMatching() config.SkillMatching {
+	matching, err := config.GetSkillMatching()
+	if err != nil {
+		return config.DefaultSkillMatching
+	}
+	return matching
+}
+
+// SkillsViolation measures how far the people are from the skill matching:
+//   - overlap: 1 if two people having skills share none of them, 0 otherwise
+//   - transfer: 1 unless an expert of a skill is with someone learning it,
+//     0 when fewer than two people have skills
+//   - complementary: always 0, complementary people are preferred rather
+//     than required, see ComplementarityViolation
+func SkillsViolation(matching config.SkillMatching, people []*types.Person) float64 {
+	switch matching.Mode {
+	case config.SkillMatchingTransfer:
+		if peopleWithSkills(people) < 2 || hasKnowledgeTransfer(people, matching.ExpertProficiency) {
+			return 0
+		}
+		return 1
+	case config.SkillMatchingComplementary:
+		return 0
+	default:
+		if haveCommonSkills(people) {
+			return 0
+		}
+		return 1
+	}
+}
+
+// ComplementarityViolation measures how far the people are from
+// complementary with complementary matching: 1 minus their complementarity,
+// so 0 when an expert is with someone not knowing the skill at all. It is
+// always 0 with other matchings.
+func ComplementarityViolation(matching config.SkillMatching, people []*types.Person) float64 {
+	if matching.Mode != config.SkillMatchingComplementary {
+		return 0
+	}
+	return 1 - Complementarity(people, matching.ExpertProficiency)
+}
+
+// haveCommonSkills returns true if every two people who both have skills
+// share at least one of them
+func haveCommonSkills(people []*types.Person) bool {
+	for i := range people {
+		for j := i + 1; j < len(people); j++ {
+			if len(people[i].Skills) != 0 && len(people[j].Skills) != 0 && len(util.Intersection(people[i].Skills.Names(), people[j].Skills.Names())) == 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// hasKnowledgeTransfer returns true if one of the people is an expert of a
+// skill another one has with a lower proficiency than an expert
+func hasKnowledgeTransfer(people []*types.Person, expertProficiency int) bool {
+	for _, expert := range people {
+		for skill, proficiency := range expert.Skills {
+			if proficiency < expertProficiency {
+				continue
+			}
+			for _, learner := range people {
+				if learner != expert && learner.Skills.Has(skill) && learner.Skills[skill] < expertProficiency {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// Complementarity rates from 0 to 1 how much the people can learn from each
+// other: the largest gap of proficiency in a skill between two of them,
+// people without the skill counting as 0, relative to the proficiency of an
+// expert
+func Complementarity(people []*types.Person, expertProficiency int) float64 {
+	if expertProficiency <= 0 {
+		return 0
+	}
+
+	largestGap := 0
+	for _, person := range people {
+		for skill, proficiency := range person.Skills {
+			for _, other := range people {
+				largestGap = max(largestGap, proficiency-other.Skills[skill])
+			}
+		}
+	}
+	return min(float64(largestGap)/float64(expertProficiency), 1)
+}
+
+// peopleWithSkills counts the people having at least one skill
+func peopleWithSkills(people []*types.Person) int {
+	count := 0
+	for _, person := range people {
+		if len(person.Skills) > 0 {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,70 @@
+package solver
+
+import (
+	"matchmaker/libs/config"
+	"matchmaker/libs/types"
+	"testing"
+)
+
+func TestSkillsViolation(t *testing.T) {
+	expert := &types.Person{Email: "expert@example.com", Skills: types.Skills{"go": 4, "java": 1}}
+	learner := &types.Person{Email: "learner@example.com", Skills: types.Skills{"go": 0}}
+	peer := &types.Person{Email: "peer@example.com", Skills: types.Skills{"go": 4}}
+	javaDev := &types.Person{Email: "java@example.com", Skills: types.Skills{"java": 2}}
+	newcomer := &types.Person{Email: "newcomer@example.com"}
+
+	overlap := config.SkillMatching{Mode: config.SkillMatchingOverlap, ExpertProficiency: 3}
+	transfer := config.SkillMatching{Mode: config.SkillMatchingTransfer, ExpertProficiency: 3}
+	complementary := config.SkillMatching{Mode: config.SkillMatchingComplementary, ExpertProficiency: 4}
+
+	tests := []struct {
+		name     string
+		matching config.SkillMatching
+		people   []*types.Person
+		want     float64
+	}{
+		{"overlap with a common skill", overlap, []*types.Person{expert, peer}, 0},
+		{"overlap without common skills", overlap, []*types.Person{peer, javaDev}, 1},
+		{"transfer from an expert to a learner", transfer, []*types.Person{expert, learner}, 0},
+		{"transfer between experts", transfer, []*types.Person{expert, peer}, 1},
+		{"transfer without a common skill", transfer, []*types.Person{peer, javaDev}, 1},
+		{"transfer with someone without skills", transfer, []*types.Person{expert, newcomer}, 0},
+		{"complementary experts", complementary, []*types.Person{expert, peer}, 0},
+		{"complementary without skills", complementary, []*types.Person{newcomer, newcomer}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SkillsViolation(tt.matching, tt.people); got != tt.want {
+				t.Errorf("SkillsViolation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComplementarityViolation(t *testing.T) {
+	expert := &types.Person{Email: "expert@example.com", Skills: types.Skills{"go": 4, "java": 1}}
+	peer := &types.Person{Email: "peer@example.com", Skills: types.Skills{"go": 4}}
+	newcomer := &types.Person{Email: "newcomer@example.com"}
+
+	overlap := config.SkillMatching{Mode: config.SkillMatchingOverlap, ExpertProficiency: 4}
+	complementary := config.SkillMatching{Mode: config.SkillMatchingComplementary, ExpertProficiency: 4}
+
+	tests := []struct {
+		name     string
+		matching config.SkillMatching
+		people   []*types.Person
+		want     float64
+	}{
+		{"expert and newcomer", complementary, []*types.Person{peer, newcomer}, 0},
+		{"experts", complementary, []*types.Person{expert, peer}, 0.75},
+		{"without skills", complementary, []*types.Person{newcomer, newcomer}, 1},
+		{"other matching", overlap, []*types.Person{newcomer, newcomer}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ComplementarityViolation(tt.matching, tt.people); got != tt.want {
+				t.Errorf("ComplementarityViolation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package solver
+
+import (
+	"matchmaker/libs/config"
+	"matchmaker/libs/types"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// slotScorer rates how convenient the time of a session is, the higher the
+// better, following the rules and points of the slot scoring configuration.
+// The weekly solver picks the session with the best slot score, and the beam
+// search uses it to break ties between plannings of equal score.
+type slotScorer struct {
+	scoring config.SlotScoring
+}
+
+// newSlotScorer returns a slot scorer following the configuration
+func newSlotScorer() slotScorer {
+	return slotScorer{scoring: config.GetSlotScoring()}
+}
+
+// score rates the time of day and the day of week of the session, and the
+// preferences of its people
+func (s slotScorer) score(session *types.ReviewSession) int {
+	startTime := session.Start()
+	score := s.timeScore(startTime, s.workingHours(startTime)) + s.dayScore(startTime)
+
+	// Penalize days and hours not preferred by the people of the squad
+	for _, person := range session.Reviewers.People {
+		if !person.PrefersDay(startTime) {
+			score += s.scoring.NotPreferred
+		}
+		if !person.PrefersHours(session.Range) {
+			score += s.scoring.NotPreferred
+		}
+	}
+	return score
+}
+
+// WorkingHours represents the configured working hours of a day
+type WorkingHours struct {
+	MorningStart   time.Time
+	MorningEnd     time.Time
+	AfternoonStart time.Time
+	AfternoonEnd   time.Time
+	LunchStart     time.Time
+	LunchEnd       time.Time
+	LastHourStart  time.Time
+}
+
+// workingHours retrieves the working hours from configuration, on the day of
+// the reference time
+func (s slotScorer) workingHours(referenceTime time.Time) WorkingHours {
+	// Get working hours from configuration
+	morningStartHour := viper.GetInt("workingHours.morning.start.hour")
+	morningStartMinute := viper.GetInt("workingHours.morning.start.minute")
+	morningEndHour := viper.GetInt("workingHours.morning.end.hour")
+	morningEndMinute := viper.GetInt("workingHours.morning.end.minute")
+
+	afternoonStartHour := viper.GetInt("workingHours.afternoon.start.hour")
+	afternoonStartMinute := viper.GetInt("workingHours.afternoon.start.minute")
+	afternoonEndHour := viper.GetInt("workingHours.afternoon.end.hour")
+	afternoonEndMinute := viper.GetInt("workingHours.afternoon.end.minute")
+
+	// Create time objects for comparison
+	morningStart := time.Date(
+		referenceTime.Year(), referenceTime.Month(), referenceTime.Day(),
+		morningStartHour, morningStartMinute, 0, 0,
+		referenceTime.Location(),
+	)
+
+	morningEnd := time.Date(
+		referenceTime.Year(), referenceTime.Month(), referenceTime.Day(),
+		morningEndHour, morningEndMinute, 0, 0,
+		referenceTime.Location(),
+	)
+
+	afternoonStart := time.Date(
+		referenceTime.Year(), referenceTime.Month(), referenceTime.Day(),
+		afternoonStartHour, afternoonStartMinute, 0, 0,
+		referenceTime.Location(),
+	)
+
+	afternoonEnd := time.Date(
+		referenceTime.Year(), referenceTime.Month(), referenceTime.Day(),
+		afternoonEndHour, afternoonEndMinute, 0, 0,
+		referenceTime.Location(),
+	)
+
+	// Define lunch break using the end of morning and start of afternoon
+	lunchStart := morningEnd
+	lunchEnd := afternoonStart
+
+	// End of the day (to avoid)
+	lastHourStart := afternoonEnd.Add(-time.Duration(s.scoring.EndOfDayMinutes) * time.Minute)
+
+	return WorkingHours{
+		MorningStart:   morningStart,
+		MorningEnd:     morningEnd,
+		AfternoonStart: afternoonStart,
+		AfternoonEnd:   afternoonEnd,
+		LunchStart:     lunchStart,
+		LunchEnd:       lunchEnd,
+		LastHourStart:  lastHourStart,
+	}
+}
+
+// isInRange checks if a time is within a range (inclusive)
+func isInRange(t, start, end time.Time) bool {
+	return !t.Before(start) && !t.After(end)
+}
+
+// timeScore calculates the score based on time of day
+func (s slotScorer) timeScore(startTime time.Time, hours WorkingHours) int {
+	score := 0
+	lunchWindow := time.Duration(s.scoring.LunchWindowMinutes) * time.Minute
+
+	// Check if session is at the end of the day (low energy)
+	if isInRange(startTime, hours.LastHourStart, hours.AfternoonEnd) {
+		score += s.scoring.EndOfDay
+	}
+
+	// Check if session is in preferred morning slot (just before lunch)
+	if isInRange(startTime, hours.LunchStart.Add(-lunchWindow), hours.LunchStart) {
+		score += s.scoring.BeforeLunch
+	}
+
+	// Check if session is in preferred afternoon slot (just after lunch)
+	if isInRange(startTime, hours.LunchEnd, hours.LunchEnd.Add(lunchWindow)) {
+		score += s.scoring.AfterLunch
+	}
+
+	// Check if session is in regular morning slot
+	if isInRange(startTime, hours.MorningStart, hours.MorningEnd) {
+		score += s.scoring.Morning
+	}
+
+	// Check if session is in regular afternoon slot
+	if isInRange(startTime, hours.AfternoonStart, hours.AfternoonEnd) {
+		score += s.scoring.Afternoon
+	}
+
+	return score
+}
+
+// dayScore calculates the score based on day of week
+func (s slotScorer) dayScore(startTime time.Time) int {
+	score := 0
+
+	weekday := startTime.Weekday()
+	if isWeekdayIn(s.scoring.AvoidedDays, weekday) {
+		score += s.scoring.AvoidedDay
+	} else {
+		score += s.scoring.OtherDay
+	}
+
+	if isWeekdayIn(s.scoring.MidWeekDays, weekday) {
+		score += s.scoring.MidWeek
+	}
+
+	return score
+}
+
+// isWeekdayIn returns true if the weekday is in the list of weekday names
+func isWeekdayIn(days []string, weekday time.Weekday) bool {
+	for _, day := range days {
+		if strings.EqualFold(strings.TrimSpace(day), weekday.String()) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,190 @@
+package solver
+
+import (
+	"matchmaker/libs/testutils"
+	"matchmaker/libs/types"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestSlotTimeScore(t *testing.T) {
+	// Create a config mock
+	configMock := testutils.NewConfigMock()
+	configMock.SetupWorkHours()
+	defer configMock.Restore()
+
+	// Get working hours for a reference time
+	referenceTime := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	scorer := newSlotScorer()
+	hours := scorer.workingHours(referenceTime)
+
+	// Print working hours for debugging
+	t.Logf("Working hours: Morning %s-%s, Afternoon %s-%s, Lunch %s-%s, Last hour starts at %s",
+		hours.MorningStart.Format("15:04"),
+		hours.MorningEnd.Format("15:04"),
+		hours.AfternoonStart.Format("15:04"),
+		hours.AfternoonEnd.Format("15:04"),
+		hours.LunchStart.Format("15:04"),
+		hours.LunchEnd.Format("15:04"),
+		hours.LastHourStart.Format("15:04"),
+	)
+
+	// Test with session at the start of preferred morning slot (1 hour before lunch)
+	// Default morning hours: 9:00-12:00, lunch at 12:00
+	// This time matches both preferred morning slot (+25) and morning slot (+10)
+	start := hours.LunchStart.Add(-1 * time.Hour) // 11:00
+	score := scorer.timeScore(start, hours)
+	t.Logf("Test 1: Time %s, Score %d", start.Format("15:04"), score)
+	if score != 35 {
+		t.Errorf("timeScore() returned score %d for start of preferred morning slot, want 35 (25 + 10)", score)
+	}
+
+	// Test with session at the end of preferred morning slot (lunch start)
+	start = hours.LunchStart // 12:00
+	score = scorer.timeScore(start, hours)
+	t.Logf("Test 2: Time %s, Score %d", start.Format("15:04"), score)
+	if score != 35 {
+		t.Errorf("timeScore() returned score %d for end of preferred morning slot, want 35 (25 + 10)", score)
+	}
+
+	// Test with session at the start of preferred afternoon slot (lunch end)
+	// Default afternoon hours: 13:00-17:00, lunch ends at 13:00
+	// This time matches both preferred afternoon slot (+25) and afternoon slot (+10)
+	start = hours.LunchEnd // 13:00
+	score = scorer.timeScore(start, hours)
+	t.Logf("Test 3: Time %s, Score %d", start.Format("15:04"), score)
+	if score != 35 {
+		t.Errorf("timeScore() returned score %d for start of preferred afternoon slot, want 35 (25 + 10)", score)
+	}
+
+	// Test with session at the end of preferred afternoon slot
+	start = hours.LunchEnd.Add(1 * time.Hour) // 14:00
+	score = scorer.timeScore(start, hours)
+	t.Logf("Test 4: Time %s, Score %d", start.Format("15:04"), score)
+	if score != 35 {
+		t.Errorf("timeScore() returned score %d for end of preferred afternoon slot, want 35 (25 + 10)", score)
+	}
+
+	// Test with session at the start of last hour
+	// Default afternoon hours: 13:00-17:00, last hour starts at 16:00
+	// This time matches both last hour (-20) and afternoon slot (+10)
+	start = hours.LastHourStart // 16:00
+	score = scorer.timeScore(start, hours)
+	t.Logf("Test 5: Time %s, Score %d", start.Format("15:04"), score)
+	if score != -10 {
+		t.Errorf("timeScore() returned score %d for start of last hour, want -10 (-20 + 10)", score)
+	}
+
+	// Test with session at the end of last hour
+	start = hours.AfternoonEnd // 17:00
+	score = scorer.timeScore(start, hours)
+	t.Logf("Test 6: Time %s, Score %d", start.Format("15:04"), score)
+	if score != -10 {
+		t.Errorf("timeScore() returned score %d for end of last hour, want -10 (-20 + 10)", score)
+	}
+
+	// Test with session at the start of regular morning slot
+	// Default morning hours: 9:00-12:00
+	// This time only matches morning slot (+10)
+	start = hours.MorningStart.Add(30 * time.Minute) // 9:30
+	score = scorer.timeScore(start, hours)
+	t.Logf("Test 7: Time %s, Score %d", start.Format("15:04"), score)
+	if score != 10 {
+		t.Errorf("timeScore() returned score %d for regular morning slot, want 10", score)
+	}
+
+	// Test with session at the end of regular morning slot
+	// Use 11:00 which is before the preferred slot starts
+	start = hours.LunchStart.Add(-2 * time.Hour) // 10:00
+	score = scorer.timeScore(start, hours)
+	t.Logf("Test 8: Time %s, Score %d", start.Format("15:04"), score)
+	if score != 10 {
+		t.Errorf("timeScore() returned score %d for regular morning slot, want 10", score)
+	}
+
+	// Test with session at the start of regular afternoon slot
+	// Use 15:00 which is after the preferred slot ends
+	start = hours.LunchEnd.Add(2 * time.Hour) // 15:00
+	score = scorer.timeScore(start, hours)
+	t.Logf("Test 9: Time %s, Score %d", start.Format("15:04"), score)
+	if score != 10 {
+		t.Errorf("timeScore() returned score %d for regular afternoon slot, want 10", score)
+	}
+
+	// Test with session at the end of regular afternoon slot
+	// Use 15:30 which is before the last hour starts
+	start = hours.LastHourStart.Add(-30 * time.Minute) // 15:30
+	score = scorer.timeScore(start, hours)
+	t.Logf("Test 10: Time %s, Score %d", start.Format("15:04"), score)
+	if score != 10 {
+		t.Errorf("timeScore() returned score %d for regular afternoon slot, want 10", score)
+	}
+
+	// Test with session outside working hours (should get no score)
+	start = hours.MorningStart.Add(-time.Hour) // 8:00
+	score = scorer.timeScore(start, hours)
+	t.Logf("Test 11: Time %s, Score %d", start.Format("15:04"), score)
+	if score != 0 {
+		t.Errorf("timeScore() returned score %d for outside working hours, want 0", score)
+	}
+}
+
+func TestSlotDayScore(t *testing.T) {
+	scorer := newSlotScorer()
+
+	// Test with Monday
+	start := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC) // Monday
+	score := scorer.dayScore(start)
+	if score > -15 {
+		t.Errorf("dayScore() returned score %d for Monday, want <= -15", score)
+	}
+
+	// Test with Friday
+	start = time.Date(2024, 4, 5, 9, 0, 0, 0, time.UTC) // Friday
+	score = scorer.dayScore(start)
+	if score > -15 {
+		t.Errorf("dayScore() returned score %d for Friday, want <= -15", score)
+	}
+
+	// Test with Wednesday
+	start = time.Date(2024, 4, 3, 9, 0, 0, 0, time.UTC) // Wednesday
+	score = scorer.dayScore(start)
+	if score < 5 {
+		t.Errorf("dayScore() returned score %d for Wednesday, want >= 5", score)
+	}
+}
+
+func TestSlotScoringConfiguration(t *testing.T) {
+	configMock := testutils.NewConfigMock()
+	configMock.SetupWorkHours()
+	defer configMock.Restore()
+
+	settings := map[string]interface{}{
+		"slotScoring.avoidedDays":  []string{"wednesday"},
+		"slotScoring.midWeek":      0,
+		"slotScoring.notPreferred": -5,
+	}
+	for key, value := range settings {
+		viper.Set(key, value)
+	}
+	defer func() {
+		for key := range settings {
+			viper.Set(key, nil)
+		}
+	}()
+
+	person1 := &types.Person{Email: "person1@example.com", PreferredDays: []string{"thursday"}}
+	person2 := &types.Person{Email: "person2@example.com"}
+	start := time.Date(2024, 4, 3, 11, 0, 0, 0, time.UTC) // Wednesday, 11:00 AM
+	session := &types.ReviewSession{
+		Reviewers: &types.Squad{People: []*types.Person{person1, person2}},
+		Range:     &types.Range{Start: start, End: start.Add(time.Hour)},
+	}
+
+	// 35 (time score) - 15 (avoided Wednesday) - 5 (day not preferred by person1) = 15
+	if score := newSlotScorer().score(session); score != 15 {
+		t.Errorf("score() with configured rules = %d, want 15", score)
+	}
+}
@@ -1,89 +1,125 @@
 package solver
 
 import (
-	"fmt"
+	"context"
 	"matchmaker/libs/config"
 	"matchmaker/libs/types"
 	"matchmaker/libs/util"
-	"os"
-	"os/signal"
+	"math/rand"
 	"sort"
 	"strconv"
 	"strings"
-	"syscall"
 	"time"
 
-	"github.com/sirupsen/logrus"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 )
 
-// These constants control the search behavior of the matching algorithm.
-// They implement a form of "beam search" or "limited breadth-first search"
-// to balance between finding good solutions and keeping computation time reasonable.
-const (
-	// maxWidthExploration limits the "width" of the search tree during exploration.
-	// It controls how many alternative solutions the algorithm will explore at each decision point.
-	// When the algorithm finds multiple possible matches, it will only explore up to this many options.
-	//
-	// Current value: 2 (explores only the 2 best alternatives at each step)
-	//
-	// Recommendations:
-	// - For small groups (10-20 people): 2-3 is sufficient
-	// - For medium groups (20-50 people): 3-5 provides better results
-	// - For large groups (50+ people): 5-10 may be needed for optimal results
-	// - Higher values improve solution quality but increase computation time exponentially
-	maxWidthExploration = 3
-
-	// maxExplorationPathLength limits the "depth" of the search tree during exploration.
-	// It controls how many sequential decisions the algorithm will make before stopping a particular path.
-	// The algorithm stops exploring any path that exceeds this length after the first decision.
-	//
-	// Current value: 10 (stops exploring paths longer than 10 steps)
-	//
-	// Recommendations:
-	// - For weekly planning: 5-10 is typically sufficient
-	// - For monthly planning: 10-15 may be needed
-	// - For quarterly planning: 15-20 could be beneficial
-	// - Higher values allow for more complex solution paths but increase computation time
-	maxExplorationPathLength = 10
-)
-
 type Solution struct {
 	Sessions []*types.ReviewSession
-}
 
-func Solve(problem *types.Problem) *Solution {
-	squads := generateSquads(problem.People, problem.BusyTimes)
-	sessionDuration := config.GetSessionDuration()
-	ranges := types.GenerateTimeRanges(problem.WorkRanges, sessionDuration)
-	sessions := types.GenerateSessions(squads, ranges)
+	// Seed is the random seed the solution was generated with, running the
+	// same problem with it produces the same planning
+	Seed int64 `yaml:"seed"`
+}
 
-	printSquads(squads)
-	printRanges(ranges)
+// Solve runs the solver strategy selected in the options on the problem, logs
+// the coverage of the resulting planning and returns it sorted by start time.
+// Cancelling the context or reaching the time limit stops the search and
+// returns the best planning found so far.
+func Solve(ctx context.Context, problem *types.Problem, options Options) (*Solution, *Stats, error) {
+	algorithm := options.Algorithm
+	if algorithm == "" {
+		algorithm = DefaultAlgorithm
+	}
 
-	bestSessions, _ := getSolver(problem, sessions)([]*types.ReviewSession{}, "")
-	solution := &Solution{
-		Sessions: bestSessions,
+	strategy, err := Get(algorithm)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	coverage, maxCoverage := getCoverage(problem.WorkRanges, bestSessions)
+	options.Parameters = resolveParameters(problem, options.Parameters)
+	options.Weights = resolveWeights(options.Weights)
+	options.Constraints = resolveConstraints(options.Constraints)
+	options.Seniority = resolveSeniority(options.Seniority)
+	if err := options.Seniority.Seniority.Validate(); err != nil {
+		return nil, nil, err
+	}
+	if err := options.Seniority.Seniority.ValidatePeople(problem.People); err != nil {
+		return nil, nil, err
+	}
+	periodSpan := options.Parameters.CoveragePeriodSpan
 
-	missingCoverage := getMissingConverage(coverage, problem.TargetCoverage)
+	if options.TimeLimit > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.TimeLimit)
+		defer cancel()
+	}
 
-	worstMissingCoverage, _ := getCoveragePerformance([]*types.ReviewSession{}, problem.WorkRanges, problem.TargetCoverage)
+	startTime := time.Now()
+	solution, stats := strategy.Solve(ctx, problem, options)
+	stats.Algorithm = algorithm
+	stats.Duration = time.Since(startTime)
+	stats.Interrupted = ctx.Err() != nil
+	stats.Parameters = options.Parameters
+	stats.Weights = options.Weights
+	stats.Constraints = options.Constraints
+	stats.Seniority = options.Seniority
+	solution.Seed = options.Seed
+
+	stats.Objective = newObjective(problem, options).evaluate(solution.Sessions)
+	stats.MissingCoverage = stats.Objective.MissingCoverage
+	stats.WorstMissingCoverage, _ = getCoveragePerformance([]*types.ReviewSession{}, problem.WorkRanges, problem.TargetCoverage, periodSpan)
+	stats.MaxCoverage = stats.Objective.MaxCoverage
+	stats.BusiestDay = busiestDay(solution.Sessions)
 
 	util.LogInfo("Coverage information", map[string]interface{}{
-		"missingCoverage":      missingCoverageToString(missingCoverage),
-		"worstMissingCoverage": missingCoverageToString(worstMissingCoverage),
-		"maxCoverage":          maxCoverage,
+		"algorithm":            stats.Algorithm,
+		"iterations":           stats.Iterations,
+		"missingCoverage":      missingCoverageToString(stats.MissingCoverage),
+		"worstMissingCoverage": missingCoverageToString(stats.WorstMissingCoverage),
+		"maxCoverage":          stats.MaxCoverage,
+		"fairness":             stats.Objective.Fairness,
+		"reviewerLoad":         stats.Objective.ReviewerLoad,
+		"repetition":           stats.Objective.Repetition,
+		"team":                 stats.Objective.Team,
+		"constraints":          stats.Objective.Constraints,
+		"busiestDay":           stats.BusiestDay,
+		"score":                stats.Objective.Score,
+		"interrupted":          stats.Interrupted,
+		"maxWidth":             options.Parameters.MaxWidthExploration,
+		"maxDepth":             options.Parameters.MaxExplorationPathLength,
+		"coveragePeriod":       periodSpan.String(),
+		"squadSize":            max(options.SquadSize, types.MinSquadSize),
+		"seed":                 options.Seed,
 	})
 
 	sort.Sort(types.ByStart(solution.Sessions))
 
 	printSessions(solution.Sessions)
 
-	return solution
+	return solution, stats, nil
+}
+
+// candidateSessions generates every session a strategy may pick for the
+// problem, in an order shuffled with the given random generator
+func candidateSessions(problem *types.Problem, options Options, r *rand.Rand) []*types.ReviewSession {
+	squadSize := max(options.SquadSize, types.MinSquadSize)
+	squads := generateSquads(problem.People, problem.BusyTimes, squadSize, r, options.Repeats, problemStart(problem), options.Seniority, options.Teams)
+	sessionDuration := config.GetSessionDuration()
+	ranges := types.GenerateTimeRanges(problem.WorkRanges, sessionDuration, r)
+
+	printSquads(squads)
+	printRanges(ranges)
+
+	return types.GenerateSessions(squads, ranges)
+}
+
+// withSession returns a copy of the sessions with the given session appended
+func withSession(sessions []*types.ReviewSession, session *types.ReviewSession) []*types.ReviewSession {
+	newSessions := make([]*types.ReviewSession, len(sessions), len(sessions)+1)
+	copy(newSessions, sessions)
+	return append(newSessions, session)
 }
 
 func printSessions(sessions []*types.ReviewSession) {
@@ -99,106 +135,6 @@ func printSession(session *types.ReviewSession) {
 	util.LogSession("Session", session)
 }
 
-type solver func([]*types.ReviewSession, string) ([]*types.ReviewSession, int)
-
-type partialSolution struct {
-	sessions []*types.ReviewSession
-	coverage int
-}
-
-type byCoverage []*partialSolution
-
-func (a byCoverage) Len() int      { return len(a) }
-func (a byCoverage) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
-func (a byCoverage) Less(i, j int) bool {
-	return isMissingCoverageBetter(a[i].coverage, a[j].coverage)
-}
-
-func getSolver(problem *types.Problem, allSessions []*types.ReviewSession) solver {
-	var solve solver
-
-	workRanges := problem.WorkRanges
-	targetCoverage := problem.TargetCoverage
-
-	bestSessions := []*types.ReviewSession{}
-	bestCoveragePerformance, _ := getCoveragePerformance(bestSessions, workRanges, targetCoverage)
-
-	var iterations int64 = 0
-
-	interrupted := false
-	sigs := make(chan os.Signal, 1)
-	signal.Notify(sigs, syscall.SIGINT)
-	go func() {
-		sig := <-sigs
-		fmt.Println()
-		fmt.Println(sig)
-		interrupted = true
-		signal.Reset(syscall.SIGINT)
-	}()
-
-	solve = func(currentSessions []*types.ReviewSession, path string) ([]*types.ReviewSession, int) {
-		derivedSolutions := []*partialSolution{}
-
-		for _, session := range allSessions {
-			if interrupted {
-				break
-			}
-
-			sessionCompatible := isSessionCompatible(session, currentSessions)
-			if !sessionCompatible {
-				continue
-			}
-
-			newSessions := append(currentSessions, session)
-			newCoveragePerformance, newMaxCoverage := getCoveragePerformance(newSessions, workRanges, targetCoverage)
-			if newMaxCoverage > problem.MaxTotalCoverage {
-				continue
-			}
-
-			derivedSolution := &partialSolution{
-				sessions: make([]*types.ReviewSession, len(newSessions)),
-				coverage: newCoveragePerformance,
-			}
-			copy(derivedSolution.sessions, newSessions)
-			derivedSolutions = append(derivedSolutions, derivedSolution)
-		}
-
-		logrus.WithFields(logrus.Fields{
-			"iterations": iterations,
-			"best":       missingCoverageToString(bestCoveragePerformance),
-			"path":       path,
-			"children":   len(derivedSolutions),
-		}).Info("Exploring children")
-
-		if len(derivedSolutions) > 0 {
-			sort.Sort(byCoverage(derivedSolutions))
-
-			newCoveragePerformance := derivedSolutions[0].coverage
-			if isMissingCoverageBetter(newCoveragePerformance, bestCoveragePerformance) {
-				newSessions := derivedSolutions[0].sessions
-				bestSessions = make([]*types.ReviewSession, len(newSessions))
-				copy(bestSessions, newSessions)
-				bestCoveragePerformance = newCoveragePerformance
-			}
-
-			for i, derivedSolution := range derivedSolutions {
-				if interrupted || i >= maxWidthExploration || i > 0 && len(path) > maxExplorationPathLength {
-					break
-				}
-
-				iterations += 1
-
-				subPath := path + "/" + strconv.Itoa(i)
-
-				solve(derivedSolution.sessions, subPath)
-			}
-		}
-
-		return bestSessions, bestCoveragePerformance
-	}
-	return solve
-}
-
 func missingCoverageToString(missingCoverage int) string {
 	return "[" + strconv.Itoa(missingCoverage) + "]"
 }
@@ -207,67 +143,6 @@ func isMissingCoverageBetter(coverage1 int, coverage2 int) bool {
 	return coverage1 <= coverage2
 }
 
-func isSessionCompatible(session *types.ReviewSession, sessions []*types.ReviewSession) bool {
-	// store the number of sessions to cap it
-	reviewers := session.Reviewers
-	people := reviewers.People
-	minSessionSpacingHours := config.GetMinSessionSpacing()
-
-	// Check for conflicts with busy times
-	for _, busyRange := range reviewers.BusyRanges {
-		if session.Range.Overlaps(busyRange) {
-			return false
-		}
-	}
-
-	person0 := people[0]
-	person0.ResetSessionCount()
-	person1 := people[1]
-	person1.ResetSessionCount()
-
-	for _, otherSession := range sessions {
-		// not the same session two times
-		if session == otherSession {
-			return false
-		}
-
-		otherReviewers := otherSession.Reviewers
-		// not the same squad
-		if reviewers == otherReviewers {
-			return false
-		}
-
-		// not the same skills (if no skills specified, the reviewer can be paired with any other reviewer)
-		if len(person0.Skills) != 0 && len(person1.Skills) != 0 && len(util.Intersection(person0.Skills, person1.Skills)) == 0 {
-			return false
-		}
-
-		otherPeople := otherReviewers.People
-
-		otherPerson0 := otherPeople[0]
-		otherPerson1 := otherPeople[1]
-		if otherPerson0 == person0 || otherPerson0 == person1 || otherPerson1 == person0 || otherPerson1 == person1 {
-			range1 := session.Range.Pad(minSessionSpacingHours)
-			range2 := otherSession.Range
-			if range1.Overlaps(range2) {
-				return false
-			}
-		}
-		// every reviewer must be able to attempt all the sessions
-		otherPerson0.IncrementSessionCount()
-		otherPerson1.IncrementSessionCount()
-	}
-
-	// check the max reviews per person
-	maxSessionsForPerson0 := person0.MaxSessionsPerWeek
-	maxSessionsForPerson1 := person1.MaxSessionsPerWeek
-	if maxSessionsForPerson0 == 0 || maxSessionsForPerson1 == 0 {
-		return false
-	}
-	return person0.GetSessionCount() < maxSessionsForPerson0 &&
-		person1.GetSessionCount() < maxSessionsForPerson1
-}
-
 func printRanges(ranges []*types.Range) {
 	for _, currentRange := range ranges {
 		util.LogRange("Range", currentRange)
@@ -276,10 +151,7 @@ func printRanges(ranges []*types.Range) {
 
 func printSquads(squads []*types.Squad) {
 	for _, squad := range squads {
-		util.LogInfo("Squad", map[string]interface{}{
-			"person1": squad.People[0].Email,
-			"person2": squad.People[1].Email,
-		})
+		util.LogInfo("Squad", util.SquadFields(squad))
 	}
 }
 
@@ -294,10 +166,8 @@ type Score struct {
 	Coverage float32
 }
 
-var coveragePeriodSpan = 30 * time.Minute
-
-func getCoveragePerformance(sessions []*types.ReviewSession, workRanges []*types.Range, target int) (int, int) {
-	coverage, maxCoverage := getCoverage(workRanges, sessions)
+func getCoveragePerformance(sessions []*types.ReviewSession, workRanges []*types.Range, target int, periodSpan time.Duration) (int, int) {
+	coverage, maxCoverage := getCoverage(workRanges, sessions, periodSpan)
 
 	missingCoverage := getMissingConverage(coverage, target)
 
@@ -314,33 +184,33 @@ func getMissingConverage(exclusivityCoverage map[int]int, targetValue int) int {
 	return missingCoverage
 }
 
-func getCoverage(workRanges []*types.Range, sessions []*types.ReviewSession) (map[int]int, int) {
+func getCoverage(workRanges []*types.Range, sessions []*types.ReviewSession, periodSpan time.Duration) (map[int]int, int) {
 	coverage := map[int]int{}
 	for _, workRange := range workRanges {
 		date := workRange.Start
 		for date.Before(workRange.End) {
-			coveragePeriodId := getCoveragePeriodId(workRanges, date)
+			coveragePeriodId := getCoveragePeriodId(workRanges, date, periodSpan)
 			coverage[coveragePeriodId] = 0
-			date = date.Add(coveragePeriodSpan)
+			date = date.Add(periodSpan)
 		}
 	}
 	maxCoverage := 0
 	for _, session := range sessions {
 		date := session.Start()
 		for date.Before(session.End()) {
-			coveragePeriodId := getCoveragePeriodId(workRanges, date)
+			coveragePeriodId := getCoveragePeriodId(workRanges, date, periodSpan)
 			coverage[coveragePeriodId] += 1
 			if coverage[coveragePeriodId] > maxCoverage {
 				maxCoverage = coverage[coveragePeriodId]
 			}
-			date = date.Add(coveragePeriodSpan)
+			date = date.Add(periodSpan)
 		}
 	}
 	return coverage, maxCoverage
 }
 
-func getCoveragePeriodId(workRanges []*types.Range, date time.Time) int {
+func getCoveragePeriodId(workRanges []*types.Range, date time.Time, periodSpan time.Duration) int {
 	elapsedNanoseconds := date.Sub(workRanges[0].Start).Nanoseconds()
-	elapsedCoveragePeriods := elapsedNanoseconds / (30 * 60 * 1000 * 1000 * 1000)
+	elapsedCoveragePeriods := elapsedNanoseconds / periodSpan.Nanoseconds()
 	return int(elapsedCoveragePeriods)
 }
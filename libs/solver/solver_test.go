@@ -1,13 +1,15 @@
 package solver
 
 import (
+	"context"
 	"matchmaker/libs/testutils"
 	"matchmaker/libs/types"
+	"math/rand"
 	"testing"
 	"time"
 )
 
-func TestIsSessionCompatible(t *testing.T) {
+func TestIsCompatible(t *testing.T) {
 	// Create a config mock
 	configMock := testutils.NewConfigMock()
 	configMock.SetupWorkHours()
@@ -153,9 +155,9 @@ func TestIsSessionCompatible(t *testing.T) {
 				tt.setup()
 			}
 
-			got := isSessionCompatible(tt.session, tt.sessions)
+			got := isCompatible(tt.session, tt.sessions)
 			if got != tt.want {
-				t.Errorf("isSessionCompatible() = %v, want %v", got, tt.want)
+				t.Errorf("isCompatible() = %v, want %v", got, tt.want)
 			}
 		})
 	}
@@ -265,7 +267,7 @@ func TestGetCoveragePeriodId(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := getCoveragePeriodId(workRanges, tt.date); got != tt.want {
+			if got := getCoveragePeriodId(workRanges, tt.date, defaultCoveragePeriodSpan); got != tt.want {
 				t.Errorf("getCoveragePeriodId() = %v, want %v", got, tt.want)
 			}
 		})
@@ -300,7 +302,7 @@ func TestGetCoverage(t *testing.T) {
 		},
 	}
 
-	coverage, maxCoverage := getCoverage(workRanges, sessions)
+	coverage, maxCoverage := getCoverage(workRanges, sessions, defaultCoveragePeriodSpan)
 
 	// Verify coverage map
 	// Expected coverage:
@@ -331,7 +333,7 @@ func TestGetCoverage(t *testing.T) {
 	}
 
 	// Test with no sessions
-	coverage, maxCoverage = getCoverage(workRanges, []*types.ReviewSession{})
+	coverage, maxCoverage = getCoverage(workRanges, []*types.ReviewSession{}, defaultCoveragePeriodSpan)
 
 	// Verify empty coverage map
 	if len(coverage) != 4 {
@@ -428,7 +430,7 @@ func TestGetCoveragePerformance(t *testing.T) {
 	}
 
 	// Test with target coverage of 1
-	missingCoverage, maxCoverage := getCoveragePerformance(sessions, workRanges, 1)
+	missingCoverage, maxCoverage := getCoveragePerformance(sessions, workRanges, 1, defaultCoveragePeriodSpan)
 
 	// Expected coverage:
 	// 9:00-9:30: 1 session (meets target)
@@ -446,7 +448,7 @@ func TestGetCoveragePerformance(t *testing.T) {
 	}
 
 	// Test with target coverage of 2
-	missingCoverage, maxCoverage = getCoveragePerformance(sessions, workRanges, 2)
+	missingCoverage, maxCoverage = getCoveragePerformance(sessions, workRanges, 2, defaultCoveragePeriodSpan)
 
 	// Expected coverage:
 	// 9:00-9:30: 1 session (missing 1)
@@ -464,7 +466,7 @@ func TestGetCoveragePerformance(t *testing.T) {
 	}
 
 	// Test with no sessions
-	missingCoverage, maxCoverage = getCoveragePerformance([]*types.ReviewSession{}, workRanges, 1)
+	missingCoverage, maxCoverage = getCoveragePerformance([]*types.ReviewSession{}, workRanges, 1, defaultCoveragePeriodSpan)
 
 	// Expected coverage:
 	// All periods missing 1
@@ -536,10 +538,11 @@ func TestGetSolver(t *testing.T) {
 	}
 
 	// Get the solver function
-	solve := getSolver(problem, sessions)
+	parameters := resolveParameters(problem, SearchParameters{})
+	solve := getSolver(context.Background(), problem, sessions, newObjective(problem, Options{Parameters: parameters}), parameters, &Stats{}, nil)
 
 	// Test with empty current sessions
-	bestSessions, bestCoverage := solve([]*types.ReviewSession{}, "")
+	bestSessions, bestCoverage := solve(newSearchState(sessions, DefaultConstraints()), "")
 
 	// Verify that we got a valid solution
 	if len(bestSessions) == 0 {
@@ -549,27 +552,27 @@ func TestGetSolver(t *testing.T) {
 	// Verify that all sessions in the solution are compatible
 	for i := 0; i < len(bestSessions)-1; i++ {
 		for j := i + 1; j < len(bestSessions); j++ {
-			if !isSessionCompatible(bestSessions[i], []*types.ReviewSession{bestSessions[j]}) {
+			if !isCompatible(bestSessions[i], []*types.ReviewSession{bestSessions[j]}) {
 				t.Errorf("Sessions %d and %d are not compatible", i, j)
 			}
 		}
 	}
 
 	// Verify that the coverage is better than the worst case
-	worstCoverage, _ := getCoveragePerformance([]*types.ReviewSession{}, workRanges, problem.TargetCoverage)
-	if bestCoverage > worstCoverage {
-		t.Errorf("getSolver() returned coverage %d, which is worse than worst case %d", bestCoverage, worstCoverage)
+	worstCoverage, _ := getCoveragePerformance([]*types.ReviewSession{}, workRanges, problem.TargetCoverage, defaultCoveragePeriodSpan)
+	if bestCoverage > float64(worstCoverage) {
+		t.Errorf("getSolver() returned coverage %v, which is worse than worst case %d", bestCoverage, worstCoverage)
 	}
 
 	// Verify that we don't exceed max total coverage
-	_, maxCoverage := getCoverage(workRanges, bestSessions)
+	_, maxCoverage := getCoverage(workRanges, bestSessions, defaultCoveragePeriodSpan)
 	if maxCoverage > problem.MaxTotalCoverage {
 		t.Errorf("getSolver() returned max coverage %d, which exceeds max total coverage %d", maxCoverage, problem.MaxTotalCoverage)
 	}
 
 	// Test with some initial sessions
 	initialSessions := []*types.ReviewSession{sessions[0]}
-	bestSessions, bestCoverage = solve(initialSessions, "")
+	bestSessions, bestCoverage = solve(newSearchState(sessions, DefaultConstraints()).withAll(initialSessions), "")
 
 	// Verify that initial sessions are included in the solution
 	found := false
@@ -586,15 +589,203 @@ func TestGetSolver(t *testing.T) {
 	// Verify that the solution with initial sessions is valid
 	for i := 0; i < len(bestSessions)-1; i++ {
 		for j := i + 1; j < len(bestSessions); j++ {
-			if !isSessionCompatible(bestSessions[i], []*types.ReviewSession{bestSessions[j]}) {
+			if !isCompatible(bestSessions[i], []*types.ReviewSession{bestSessions[j]}) {
 				t.Errorf("Sessions %d and %d are not compatible in solution with initial sessions", i, j)
 			}
 		}
 	}
 
 	// Verify that the coverage with initial sessions is better than the worst case
-	worstCoverage, _ = getCoveragePerformance(initialSessions, workRanges, problem.TargetCoverage)
-	if bestCoverage > worstCoverage {
-		t.Errorf("getSolver() with initial sessions returned coverage %d, which is worse than worst case %d", bestCoverage, worstCoverage)
+	worstCoverage, _ = getCoveragePerformance(initialSessions, workRanges, problem.TargetCoverage, defaultCoveragePeriodSpan)
+	if bestCoverage > float64(worstCoverage) {
+		t.Errorf("getSolver() with initial sessions returned coverage %v, which is worse than worst case %d", bestCoverage, worstCoverage)
+	}
+}
+
+func TestGetSolverSlotTieBreak(t *testing.T) {
+	configMock := testutils.NewConfigMock()
+	configMock.SetupWorkHours()
+	defer configMock.Restore()
+
+	person1 := &types.Person{Email: "person1@example.com", MaxSessionsPerWeek: 1}
+	person2 := &types.Person{Email: "person2@example.com", MaxSessionsPerWeek: 1}
+	squad := &types.Squad{People: []*types.Person{person1, person2}}
+
+	// Both sessions cover as much, the Wednesday one has the better slot
+	monday := time.Date(2024, 4, 1, 16, 0, 0, 0, time.UTC)
+	wednesday := time.Date(2024, 4, 3, 11, 0, 0, 0, time.UTC)
+	workRanges := []*types.Range{
+		{Start: monday, End: monday.Add(time.Hour)},
+		{Start: wednesday, End: wednesday.Add(time.Hour)},
+	}
+	sessions := []*types.ReviewSession{
+		{Reviewers: squad, Range: workRanges[0]},
+		{Reviewers: squad, Range: workRanges[1]},
+	}
+	problem := &types.Problem{
+		People:           []*types.Person{person1, person2},
+		WorkRanges:       workRanges,
+		TargetCoverage:   1,
+		MaxTotalCoverage: 2,
+	}
+
+	parameters := resolveParameters(problem, SearchParameters{})
+	solve := getSolver(context.Background(), problem, sessions, newObjective(problem, Options{Parameters: parameters}), parameters, &Stats{}, nil)
+	bestSessions, _ := solve(newSearchState(sessions, DefaultConstraints()), "")
+
+	if len(bestSessions) != 1 || bestSessions[0] != sessions[1] {
+		t.Errorf("getSolver() returned %v, want the Wednesday session", bestSessions)
+	}
+}
+
+func TestGetSolverCancelled(t *testing.T) {
+	configMock := testutils.NewConfigMock()
+	configMock.SetupWorkHours()
+	defer configMock.Restore()
+
+	problem := newTestProblem()
+	sessions := candidateSessions(problem, Options{}, rand.New(rand.NewSource(1)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	stats := &Stats{}
+	parameters := resolveParameters(problem, SearchParameters{})
+	bestSessions, bestCoverage := getSolver(ctx, problem, sessions, newObjective(problem, Options{Parameters: parameters}), parameters, stats, nil)(newSearchState(sessions, DefaultConstraints()), "")
+
+	if len(bestSessions) != 0 {
+		t.Errorf("getSolver() with cancelled context returned %d sessions, want 0", len(bestSessions))
+	}
+	if stats.Iterations != 0 {
+		t.Errorf("getSolver() with cancelled context explored %d branches, want 0", stats.Iterations)
+	}
+
+	worstCoverage, _ := getCoveragePerformance([]*types.ReviewSession{}, problem.WorkRanges, problem.TargetCoverage, defaultCoveragePeriodSpan)
+	if bestCoverage != float64(worstCoverage) {
+		t.Errorf("getSolver() with cancelled context returned coverage %v, want %d", bestCoverage, worstCoverage)
+	}
+}
+
+func TestSolveCancelled(t *testing.T) {
+	configMock := testutils.NewConfigMock()
+	configMock.SetupWorkHours()
+	defer configMock.Restore()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, name := range Algorithms() {
+		solution, stats, err := Solve(ctx, newTestProblem(), Options{Algorithm: name})
+		if err != nil {
+			t.Fatalf("Solve(%q) returned error: %v", name, err)
+		}
+		if solution == nil {
+			t.Errorf("Solve(%q) with cancelled context returned nil solution", name)
+		}
+		if stats.Optimal {
+			t.Errorf("Solve(%q) with cancelled context claims a proven optimal planning", name)
+		}
+	}
+}
+
+func TestSolveTimeLimit(t *testing.T) {
+	configMock := testutils.NewConfigMock()
+	configMock.SetupWorkHours()
+	defer configMock.Restore()
+
+	solution, stats, err := Solve(context.Background(), newTestProblem(), Options{
+		Algorithm: "anneal",
+		TimeLimit: time.Nanosecond,
+	})
+	if err != nil {
+		t.Fatalf("Solve() returned error: %v", err)
+	}
+	if solution == nil {
+		t.Fatal("Solve() with time limit returned nil solution")
+	}
+	if !stats.Interrupted {
+		t.Error("Solve() with an elapsed time limit was not reported as interrupted")
+	}
+
+	_, stats, err = Solve(context.Background(), newTestProblem(), Options{Algorithm: "greedy"})
+	if err != nil {
+		t.Fatalf("Solve() returned error: %v", err)
+	}
+	if stats.Interrupted {
+		t.Error("Solve() without time limit was reported as interrupted")
+	}
+}
+
+func TestSolveUnknownAlgorithm(t *testing.T) {
+	if _, _, err := Solve(context.Background(), newTestProblem(), Options{Algorithm: "unknown"}); err == nil {
+		t.Error("Solve() returned no error for unknown algorithm")
+	}
+}
+
+func TestIsPathBefore(t *testing.T) {
+	tests := []struct {
+		a    string
+		b    string
+		want bool
+	}{
+		{a: "", b: "/0", want: true},
+		{a: "/0", b: "", want: false},
+		{a: "/0/1", b: "/1", want: true},
+		{a: "/2", b: "/10", want: true},
+		{a: "/1/0", b: "/1", want: false},
+		{a: "/1", b: "/1", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := isPathBefore(tt.a, tt.b); got != tt.want {
+			t.Errorf("isPathBefore(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestIsCompatibleTrio(t *testing.T) {
+	configMock := testutils.NewConfigMock()
+	configMock.SetupWorkHours()
+	defer configMock.Restore()
+
+	person1 := &types.Person{Email: "person1@example.com", MaxSessionsPerWeek: 2}
+	person2 := &types.Person{Email: "person2@example.com", MaxSessionsPerWeek: 2}
+	person3 := &types.Person{Email: "person3@example.com", MaxSessionsPerWeek: 1}
+	person4 := &types.Person{Email: "person4@example.com", MaxSessionsPerWeek: 2}
+
+	start := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	monday := &types.Range{Start: start, End: start.Add(time.Hour)}
+	wednesday := &types.Range{Start: start.AddDate(0, 0, 2), End: start.AddDate(0, 0, 2).Add(time.Hour)}
+
+	trio := &types.ReviewSession{
+		Reviewers: &types.Squad{People: []*types.Person{person1, person2, person3}},
+		Range:     monday,
+	}
+
+	// The third person of the trio reached their weekly maximum
+	other := &types.ReviewSession{
+		Reviewers: &types.Squad{People: []*types.Person{person4, person3}},
+		Range:     wednesday,
+	}
+	if isCompatible(trio, []*types.ReviewSession{other}) {
+		t.Error("isCompatible() accepted a trio whose third person has no session left")
+	}
+
+	// The third person of the trio is busy at the same time
+	other = &types.ReviewSession{
+		Reviewers: &types.Squad{People: []*types.Person{person4, person3}},
+		Range:     monday,
+	}
+	if isCompatible(trio, []*types.ReviewSession{other}) {
+		t.Error("isCompatible() accepted a trio overlapping another session of its third person")
+	}
+
+	// No person in common
+	other = &types.ReviewSession{
+		Reviewers: &types.Squad{People: []*types.Person{person4, person1}},
+		Range:     wednesday,
+	}
+	if !isCompatible(trio, []*types.ReviewSession{other}) {
+		t.Error("isCompatible() rejected a trio compatible with the planning")
 	}
 }
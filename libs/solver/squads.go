@@ -7,40 +7,63 @@ import (
 	"matchmaker/libs/types"
 	"matchmaker/libs/util"
 	"math/rand"
+	"sort"
+	"time"
 )
 
 // Squads is a slice of Squad pointers
 type Squads []*types.Squad
 
-func generateSquads(people []*types.Person, busyTimes []*types.BusyTime) []*types.Squad {
-	masters := filterPersons(people, true)
-	disciples := filterPersons(people, false)
+// generateSquads builds every squad of squadSize people following the
+// seniority rules, in an order shuffled with the given random generator.
+// Squads of people avoiding each other, or leaving out the required partner of
+// one of them, are left out. Squads whose people were paired recently
+// according to the repeat policy get a repeat penalty, or are left out when
+// the policy forbids them, unless they include a required pair. The team
+// policy likewise penalizes or leaves out squads according to the teams of
+// their people, unless they include a required pair.
+func generateSquads(people []*types.Person, busyTimes []*types.BusyTime, squadSize int, r *rand.Rand, repeats RepeatPolicy, reference time.Time, seniority SeniorityRules, teams TeamPolicy) []*types.Squad {
+	seniority = resolveSeniority(seniority)
 
-	squads := []*types.Squad{}
-	for _, master := range masters {
-		for _, disciple := range disciples {
-			people := []*types.Person{master, disciple}
-			squads = append(squads, &types.Squad{
-				People:     people,
-				BusyRanges: mergeBusyRanges(busyTimes, people),
-			})
-		}
+	// the most senior people come first, so that squads starting with one of
+	// the people at the minimum level are exactly the squads including one
+	candidates := append([]*types.Person{}, people...)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return seniority.Seniority.PersonRank(candidates[i]) > seniority.Seniority.PersonRank(candidates[j])
+	})
+	firstLimit := 0
+	for firstLimit < len(candidates) && seniority.Seniority.PersonRank(candidates[firstLimit]) >= seniority.minRank() {
+		firstLimit++
 	}
 
-	for masterIndex1, master1 := range masters {
-		for masterIndex2, master2 := range masters {
-			if masterIndex1 < masterIndex2 {
-				people := []*types.Person{master1, master2}
-				squads = append(squads, &types.Squad{
-					People:     people,
-					BusyRanges: mergeBusyRanges(busyTimes, people),
-				})
-			}
+	squads := []*types.Squad{}
+	for _, people := range combinations(candidates, squadSize, firstLimit) {
+		if !seniority.allowsGap(people) {
+			util.LogInfo("Squad levels too far apart, skipping", util.SquadFields(&types.Squad{People: people}))
+			continue
+		}
+		if !types.PairingAllowed(people, candidates) {
+			util.LogInfo("Squad not allowed by avoid or pairwith, skipping", util.SquadFields(&types.Squad{People: people}))
+			continue
+		}
+		if !types.PairingRequired(people) && !repeats.Allows(people, reference) {
+			util.LogInfo("Squad paired recently, skipping", util.SquadFields(&types.Squad{People: people}))
+			continue
+		}
+		if !types.PairingRequired(people) && !teams.Allows(people) {
+			util.LogInfo("Squad not allowed by team policy, skipping", util.SquadFields(&types.Squad{People: people}))
+			continue
 		}
+		squads = append(squads, &types.Squad{
+			People:        people,
+			BusyRanges:    mergeBusyRanges(busyTimes, people),
+			RepeatPenalty: repeats.Penalty(people, reference),
+			TeamPenalty:   teams.Penalty(people),
+		})
 	}
 
 	for i := range squads {
-		j := rand.Intn(i + 1)
+		j := r.Intn(i + 1)
 		squads[i], squads[j] = squads[j], squads[i]
 	}
 
@@ -48,10 +71,7 @@ func generateSquads(people []*types.Person, busyTimes []*types.BusyTime) []*type
 		"count": len(squads),
 	})
 	for i := range squads {
-		util.LogInfo("Squad", map[string]interface{}{
-			"person1": squads[i].People[0].Email,
-			"person2": squads[i].People[1].Email,
-		})
+		util.LogInfo("Squad", util.SquadFields(squads[i]))
 		util.LogInfo("Busy ranges", nil)
 		for j := range squads[i].BusyRanges {
 			util.LogRange("Busy range", squads[i].BusyRanges[j])
@@ -60,13 +80,29 @@ func generateSquads(people []*types.Person, busyTimes []*types.BusyTime) []*type
 	return squads
 }
 
-func filterPersons(people []*types.Person, isGoodReviewer bool) []*types.Person {
-	result := []*types.Person{}
-	for _, person := range people {
-		if person.IsGoodReviewer == isGoodReviewer {
-			result = append(result, person)
+// combinations returns every combination of size people, keeping their
+// order, whose first person has an index lower than firstLimit
+func combinations(people []*types.Person, size int, firstLimit int) [][]*types.Person {
+	result := [][]*types.Person{}
+	if size <= 0 {
+		return result
+	}
+
+	var combine func(from int, combination []*types.Person)
+	combine = func(from int, combination []*types.Person) {
+		if len(combination) == size {
+			result = append(result, append([]*types.Person{}, combination...))
+			return
+		}
+		for i := from; i <= len(people)-(size-len(combination)); i++ {
+			if len(combination) == 0 && i >= firstLimit {
+				break
+			}
+			combine(i+1, append(combination, people[i]))
 		}
 	}
+	combine(0, make([]*types.Person, 0, size))
+
 	return result
 }
 
@@ -84,9 +120,6 @@ func mergeBusyRanges(busyTimes []*types.BusyTime, people []*types.Person) []*typ
 
 func (squads Squads) Print() {
 	for _, squad := range squads {
-		util.LogInfo("Squad", map[string]interface{}{
-			"person1": squad.People[0].Email,
-			"person2": squad.People[1].Email,
-		})
+		util.LogInfo("Squad", util.SquadFields(squad))
 	}
 }
@@ -2,6 +2,8 @@ package solver
 
 import (
 	"matchmaker/libs/types"
+	"math/rand"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -31,7 +33,7 @@ func TestGenerateSquads(t *testing.T) {
 	}
 
 	// Test with no busy times
-	squads := generateSquads([]*types.Person{person1, person2, person3, person4}, []*types.BusyTime{})
+	squads := generateSquads([]*types.Person{person1, person2, person3, person4}, []*types.BusyTime{}, 2, rand.New(rand.NewSource(1)), RepeatPolicy{}, time.Time{}, DefaultSeniorityRules(), TeamPolicy{})
 
 	// Verify that we got the correct number of squads
 	// Expected: 2 masters (person1, person3) and 2 disciples (person2, person4)
@@ -43,7 +45,7 @@ func TestGenerateSquads(t *testing.T) {
 	}
 
 	// Test with busy times
-	squads = generateSquads([]*types.Person{person1, person2, person3, person4}, []*types.BusyTime{busyTime1, busyTime2})
+	squads = generateSquads([]*types.Person{person1, person2, person3, person4}, []*types.BusyTime{busyTime1, busyTime2}, 2, rand.New(rand.NewSource(1)), RepeatPolicy{}, time.Time{}, DefaultSeniorityRules(), TeamPolicy{})
 
 	// Verify that we got the correct number of squads
 	if len(squads) != 5 {
@@ -99,23 +101,58 @@ func TestGenerateSquads(t *testing.T) {
 	}
 }
 
-func TestFilterPersons(t *testing.T) {
-	// Create test persons
-	person1 := &types.Person{Email: "person1@example.com", IsGoodReviewer: true}
-	person2 := &types.Person{Email: "person2@example.com", IsGoodReviewer: false}
-	person3 := &types.Person{Email: "person3@example.com", IsGoodReviewer: true}
-	person4 := &types.Person{Email: "person4@example.com", IsGoodReviewer: false}
+func TestGenerateSquadsSeniority(t *testing.T) {
+	staff := &types.Person{Email: "staff@example.com", Level: "staff"}
+	senior := &types.Person{Email: "senior@example.com", IsGoodReviewer: true}
+	mid := &types.Person{Email: "mid@example.com", Level: "mid"}
+	junior := &types.Person{Email: "junior@example.com"}
+	people := []*types.Person{junior, mid, senior, staff}
 
-	// Test with isGoodReviewer = true
-	masters := filterPersons([]*types.Person{person1, person2, person3, person4}, true)
-	if len(masters) != 2 {
-		t.Errorf("filterPersons() returned %d masters, want 2", len(masters))
+	squadNames := func(rules SeniorityRules) map[string]bool {
+		names := map[string]bool{}
+		for _, squad := range generateSquads(people, []*types.BusyTime{}, 2, rand.New(rand.NewSource(1)), RepeatPolicy{}, time.Time{}, rules, TeamPolicy{}) {
+			names[squad.GetDisplayName()] = true
+		}
+		return names
 	}
 
-	// Test with isGoodReviewer = false
-	disciples := filterPersons([]*types.Person{person1, person2, person3, person4}, false)
-	if len(disciples) != 2 {
-		t.Errorf("filterPersons() returned %d disciples, want 2", len(disciples))
+	// Good reviewers without a level are seniors, every squad has one
+	// senior or more, most senior first
+	want := map[string]bool{
+		"staff & senior":  true,
+		"staff & mid":     true,
+		"staff & junior":  true,
+		"senior & mid":    true,
+		"senior & junior": true,
+	}
+	if got := squadNames(DefaultSeniorityRules()); !reflect.DeepEqual(got, want) {
+		t.Errorf("generateSquads() with default rules = %v, want %v", got, want)
+	}
+
+	rules := SeniorityRules{Seniority: types.DefaultSeniority, MaxLevelGap: 1}
+	want = map[string]bool{
+		"staff & senior": true,
+		"senior & mid":   true,
+		"mid & junior":   true,
+	}
+	if got := squadNames(rules); !reflect.DeepEqual(got, want) {
+		t.Errorf("generateSquads() with a max level gap of 1 = %v, want %v", got, want)
+	}
+
+	rules = SeniorityRules{Seniority: types.DefaultSeniority, MinLevel: "mid", MaxLevelGap: 2}
+	want = map[string]bool{
+		"staff & senior":  true,
+		"staff & mid":     true,
+		"senior & mid":    true,
+		"senior & junior": true,
+		"mid & junior":    true,
+	}
+	if got := squadNames(rules); !reflect.DeepEqual(got, want) {
+		t.Errorf("generateSquads() with at least a mid and a max level gap of 2 = %v, want %v", got, want)
+	}
+
+	if got, want := rules.String(), "junior < mid < senior < staff (at least one mid or above, max level gap 2)"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
 	}
 }
 
@@ -271,3 +308,104 @@ func TestHaveIntersection(t *testing.T) {
 		t.Error("Overlaps() returned true for adjacent ranges")
 	}
 }
+
+func TestGenerateSquadsRepeats(t *testing.T) {
+	person1 := &types.Person{Email: "person1@example.com", IsGoodReviewer: true}
+	person2 := &types.Person{Email: "person2@example.com", IsGoodReviewer: false}
+	person3 := &types.Person{Email: "person3@example.com", IsGoodReviewer: false}
+	people := []*types.Person{person1, person2, person3}
+
+	reference := time.Date(2024, 4, 29, 0, 0, 0, 0, time.UTC)
+	history := &types.PairingHistory{}
+	history.Add([]string{person1.Email, person2.Email}, reference.AddDate(0, 0, -3), "", "")
+
+	// Penalized repeats are kept with a penalty
+	squads := generateSquads(people, []*types.BusyTime{}, 2, rand.New(rand.NewSource(1)), RepeatPolicy{History: history, Weeks: 4}, reference, DefaultSeniorityRules(), TeamPolicy{})
+	if len(squads) != 2 {
+		t.Fatalf("generateSquads() returned %d squads, want 2", len(squads))
+	}
+	for _, squad := range squads {
+		want := 0.0
+		if squad.People[1] == person2 {
+			want = 1
+		}
+		if squad.RepeatPenalty != want {
+			t.Errorf("generateSquads() squad with %s has repeat penalty %v, want %v", squad.People[1].Email, squad.RepeatPenalty, want)
+		}
+	}
+
+	// Forbidden repeats are left out
+	squads = generateSquads(people, []*types.BusyTime{}, 2, rand.New(rand.NewSource(1)), RepeatPolicy{History: history, Weeks: 4, Forbid: true}, reference, DefaultSeniorityRules(), TeamPolicy{})
+	if len(squads) != 1 || squads[0].People[1] != person3 {
+		t.Errorf("generateSquads() with forbidden repeats returned %d squads, want only the squad with person3", len(squads))
+	}
+}
+
+func TestGenerateSquadsOfThree(t *testing.T) {
+	master1 := &types.Person{Email: "master1@example.com", IsGoodReviewer: true}
+	master2 := &types.Person{Email: "master2@example.com", IsGoodReviewer: true}
+	disciple1 := &types.Person{Email: "disciple1@example.com"}
+	disciple2 := &types.Person{Email: "disciple2@example.com"}
+	disciple3 := &types.Person{Email: "disciple3@example.com"}
+	people := []*types.Person{master1, master2, disciple1, disciple2, disciple3}
+
+	// Every trio but the one made of the 3 disciples
+	squads := generateSquads(people, []*types.BusyTime{}, 3, rand.New(rand.NewSource(1)), RepeatPolicy{}, time.Time{}, DefaultSeniorityRules(), TeamPolicy{})
+	if len(squads) != 9 {
+		t.Fatalf("generateSquads() returned %d trios, want 9", len(squads))
+	}
+
+	for _, squad := range squads {
+		if len(squad.People) != 3 {
+			t.Errorf("generateSquads() returned a squad of %d people, want 3", len(squad.People))
+		}
+		if err := squad.Validate(); err != nil {
+			t.Errorf("generateSquads() returned an invalid squad: %v", err)
+		}
+		if !squad.People[0].IsGoodReviewer {
+			t.Errorf("generateSquads() returned squad %s without a good reviewer first", squad.GetDisplayName())
+		}
+	}
+
+	// No squad is larger than the group
+	squads = generateSquads(people, []*types.BusyTime{}, 6, rand.New(rand.NewSource(1)), RepeatPolicy{}, time.Time{}, DefaultSeniorityRules(), TeamPolicy{})
+	if len(squads) != 0 {
+		t.Errorf("generateSquads() returned %d squads of 6 people out of 5, want 0", len(squads))
+	}
+}
+
+func TestGenerateSquadsAvoidPairWith(t *testing.T) {
+	manager := &types.Person{Email: "manager@example.com", IsGoodReviewer: true}
+	buddy := &types.Person{Email: "buddy@example.com", IsGoodReviewer: true}
+	report := &types.Person{Email: "report@example.com", Avoid: []string{"manager@example.com"}}
+	newcomer := &types.Person{Email: "newcomer@example.com", PairWith: []string{"buddy@example.com"}}
+	people := []*types.Person{manager, buddy, report, newcomer}
+
+	reference := time.Date(2024, 4, 29, 0, 0, 0, 0, time.UTC)
+	history := &types.PairingHistory{}
+	history.Add([]string{buddy.Email, newcomer.Email}, reference.AddDate(0, 0, -3), "", "")
+
+	// Left: manager & buddy, buddy & report, buddy & newcomer despite the forbidden repeat
+	squads := generateSquads(people, []*types.BusyTime{}, 2, rand.New(rand.NewSource(1)), RepeatPolicy{History: history, Weeks: 4, Forbid: true}, reference, DefaultSeniorityRules(), TeamPolicy{})
+	names := map[string]bool{}
+	for _, squad := range squads {
+		names[squad.GetDisplayName()] = true
+	}
+	want := map[string]bool{
+		"manager & buddy":  true,
+		"buddy & report":   true,
+		"buddy & newcomer": true,
+	}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("generateSquads() = %v, want %v", names, want)
+	}
+
+	// Sessions of squads built elsewhere are checked as well
+	session := &types.ReviewSession{
+		Reviewers: &types.Squad{People: []*types.Person{manager, report}},
+		Range:     &types.Range{Start: reference, End: reference.Add(time.Hour)},
+	}
+	if isCompatible(session, []*types.ReviewSession{}) {
+		t.Error("isCompatible() accepted people avoiding each other")
+	}
+}
@@ -0,0 +1,315 @@
+package solver
+
+import "matchmaker/libs/types"
+
+// timeline lists sessions, latest added first. Nodes are never modified, so
+// timelines are shared between search states.
+type timeline struct {
+	session *types.ReviewSession
+	count   int
+	next    *timeline
+}
+
+// with returns the timeline, which may be nil, with the session added
+func (t *timeline) with(session *types.ReviewSession) *timeline {
+	count := 1
+	if t != nil {
+		count = t.count + 1
+	}
+	return &timeline{session: session, count: count, next: t}
+}
+
+// length returns the number of sessions of the timeline, which may be nil
+func (t *timeline) length() int {
+	if t == nil {
+		return 0
+	}
+	return t.count
+}
+
+// peopleIndex assigns each person of the candidate sessions a position in the
+// timelines of search states, and each coverage period a position in their
+// coverage. It holds the rules sessions must follow and what each candidate
+// session adds to the objective. It is read-only once built.
+type peopleIndex struct {
+	people      []*types.Person
+	positions   map[*types.Person]int
+	constraints Constraints
+
+	// pairings caches whether the squad of each candidate session may be
+	// paired, which only depends on the squad and the people of the index
+	pairings map[*types.Squad]bool
+
+	// loads is the reviewer load added by a session of each person
+	loads []float64
+
+	// periods lists the positions of the coverage periods spanned by each
+	// candidate session. periodIds gives the coverage period of each position,
+	// and workPeriods the ones within the work ranges.
+	periods     map[*types.ReviewSession][]int
+	periodIds   []int
+	workPeriods []bool
+	target      int
+
+	// missingCoverage is the missing coverage of the empty planning
+	missingCoverage int
+}
+
+// searchState is an immutable planning under construction. It tracks the
+// sessions of each person, so that checking a candidate session only looks at
+// the few sessions of its attendees, and the running totals of the
+// objective, so that scoring it doesn't look at the other sessions nor
+// people. Adding a session returns a new state sharing its timelines and
+// coverage with the previous one, only copying the entries of the session,
+// which makes states safe to explore concurrently.
+type searchState struct {
+	index *peopleIndex
+
+	// sessions lists the planned sessions, latest added first
+	sessions  *timeline
+	timelines persistentArray[*timeline]
+
+	// coverage counts the sessions spanning each coverage period
+	coverage persistentArray[int]
+
+	// penalty sums the weighted violations of soft rules by the sessions
+	penalty float64
+
+	missingCoverage int
+	maxCoverage     int
+
+	// sessionCounts sums the number of sessions of each person, and
+	// squaredCounts its square
+	sessionCounts int
+	squaredCounts int
+
+	reviewerLoad float64
+	repetition   float64
+	team         float64
+}
+
+// newSearchState returns an empty planning for the people of the given
+// candidate sessions, following the given rules. It doesn't keep the
+// coverage nor the reviewer load, see objective.newState.
+func newSearchState(candidates []*types.ReviewSession, constraints Constraints) *searchState {
+	return newPeopleIndex(candidates, constraints, nil).empty()
+}
+
+// newPeopleIndex indexes the people of the candidate sessions, and the
+// coverage periods and reviewer load of the objective, if any
+func newPeopleIndex(candidates []*types.ReviewSession, constraints Constraints, objective *objective) *peopleIndex {
+	index := &peopleIndex{
+		positions:   map[*types.Person]int{},
+		constraints: constraints,
+		pairings:    map[*types.Squad]bool{},
+		periods:     map[*types.ReviewSession][]int{},
+	}
+	for _, session := range candidates {
+		for _, person := range session.Reviewers.People {
+			if _, ok := index.positions[person]; !ok {
+				index.positions[person] = len(index.people)
+				index.people = append(index.people, person)
+			}
+		}
+	}
+	for _, session := range candidates {
+		if _, ok := index.pairings[session.Reviewers]; !ok {
+			index.pairings[session.Reviewers] = types.PairingAllowed(session.Reviewers.People, index.people)
+		}
+	}
+
+	index.loads = make([]float64, len(index.people))
+	if objective == nil {
+		return index
+	}
+	for position, person := range index.people {
+		index.loads[position] = reviewerLoad(person, objective.seniority)
+	}
+
+	workRanges := objective.problem.WorkRanges
+	if len(workRanges) == 0 || objective.periodSpan <= 0 {
+		return index
+	}
+	index.target = objective.problem.TargetCoverage
+	positions := map[int]int{}
+	position := func(period int) int {
+		if _, ok := positions[period]; !ok {
+			positions[period] = len(index.periodIds)
+			index.periodIds = append(index.periodIds, period)
+			index.workPeriods = append(index.workPeriods, false)
+		}
+		return positions[period]
+	}
+	for _, workRange := range workRanges {
+		for date := workRange.Start; date.Before(workRange.End); date = date.Add(objective.periodSpan) {
+			index.workPeriods[position(getCoveragePeriodId(workRanges, date, objective.periodSpan))] = true
+		}
+	}
+	for _, session := range candidates {
+		periods := []int{}
+		for _, period := range sessionPeriods(workRanges, session, objective.periodSpan) {
+			periods = append(periods, position(period))
+		}
+		index.periods[session] = periods
+	}
+	for position := range index.periodIds {
+		index.missingCoverage += index.periodMissingCoverage(position, 0)
+	}
+	return index
+}
+
+// empty returns an empty planning of the index
+func (i *peopleIndex) empty() *searchState {
+	return &searchState{
+		index:           i,
+		timelines:       newPersistentArray[*timeline](len(i.people)),
+		coverage:        newPersistentArray[int](len(i.periodIds)),
+		missingCoverage: i.missingCoverage,
+	}
+}
+
+// periodMissingCoverage returns the coverage missing to reach the target in
+// the period at the given position, spanned by the given number of sessions.
+// Periods out of the work ranges only count once a session spans them.
+func (i *peopleIndex) periodMissingCoverage(position int, count int) int {
+	if !i.workPeriods[position] && count == 0 {
+		return 0
+	}
+	return max(0, i.target-count)
+}
+
+// empty returns an empty planning for the same people
+func (s *searchState) empty() *searchState {
+	return s.index.empty()
+}
+
+// with returns a new state with the session added to the planning. The
+// session is expected to be a candidate compatible with the planning, see
+// canAdd. It costs O(log n) per person and coverage period of the session.
+func (s *searchState) with(session *types.ReviewSession) *searchState {
+	next := *s
+	next.sessions = s.sessions.with(session)
+
+	for _, person := range session.Reviewers.People {
+		position := s.index.positions[person]
+		personTimeline := next.timelines.get(position).with(session)
+		next.timelines = next.timelines.set(position, personTimeline)
+
+		// (n+1)² - n² = 2n + 1
+		next.sessionCounts++
+		next.squaredCounts += 2*personTimeline.count - 1
+		next.reviewerLoad += s.index.loads[position]
+	}
+
+	for _, position := range s.index.periods[session] {
+		count := next.coverage.get(position) + 1
+		next.coverage = next.coverage.set(position, count)
+		next.missingCoverage += s.index.periodMissingCoverage(position, count) - s.index.periodMissingCoverage(position, count-1)
+		next.maxCoverage = max(next.maxCoverage, count)
+	}
+
+	next.repetition += session.Reviewers.RepeatPenalty
+	next.team += session.Reviewers.TeamPenalty
+	next.penalty += s.index.constraints.penalty(s, session)
+	return &next
+}
+
+// withAll returns a new state with all the sessions added to the planning
+func (s *searchState) withAll(sessions []*types.ReviewSession) *searchState {
+	state := s
+	for _, session := range sessions {
+		state = state.with(session)
+	}
+	return state
+}
+
+// size returns the number of planned sessions
+func (s *searchState) size() int {
+	return s.sessions.length()
+}
+
+// sessionCount returns the number of sessions planned for a person
+func (s *searchState) sessionCount(person *types.Person) int {
+	position, ok := s.index.positions[person]
+	if !ok {
+		return 0
+	}
+	return s.timelines.get(position).length()
+}
+
+// coverageByPeriod returns the number of sessions spanning each coverage
+// period of the work ranges or of the planned sessions, see getCoverage
+func (s *searchState) coverageByPeriod() map[int]int {
+	coverage := map[int]int{}
+	for position, period := range s.index.periodIds {
+		count := s.coverage.get(position)
+		if s.index.workPeriods[position] || count > 0 {
+			coverage[period] = count
+		}
+	}
+	return coverage
+}
+
+// Sessions returns the planned sessions, in the order they were added
+func (s *searchState) Sessions() []*types.ReviewSession {
+	sessions := make([]*types.ReviewSession, s.size())
+	i := len(sessions) - 1
+	for node := s.sessions; node != nil; node = node.next {
+		sessions[i] = node.session
+		i--
+	}
+	return sessions
+}
+
+// PersonSessions returns the planned sessions attended by a person, latest
+// added first
+func (s *searchState) PersonSessions(person *types.Person) []*types.ReviewSession {
+	position, ok := s.index.positions[person]
+	if !ok {
+		return nil
+	}
+	personTimeline := s.timelines.get(position)
+	if personTimeline == nil {
+		return nil
+	}
+
+	sessions := make([]*types.ReviewSession, 0, personTimeline.count)
+	for node := personTimeline; node != nil; node = node.next {
+		sessions = append(sessions, node.session)
+	}
+	return sessions
+}
+
+// canAdd returns true if the session can be added to the planning: it isn't
+// planned yet, its squad has no other session nor people avoiding each other
+// or missing a required partner, and it violates no hard rule
+func (s *searchState) canAdd(session *types.ReviewSession) bool {
+	reviewers := session.Reviewers
+	if !s.index.pairingAllowed(reviewers) {
+		return false
+	}
+
+	for _, person := range reviewers.People {
+		position, ok := s.index.positions[person]
+		if !ok {
+			return false
+		}
+
+		for node := s.timelines.get(position); node != nil; node = node.next {
+			// not the same session two times, nor the same squad
+			if node.session == session || node.session.Reviewers == reviewers {
+				return false
+			}
+		}
+	}
+	return s.index.constraints.allows(s, session)
+}
+
+// pairingAllowed returns true if the people of the squad may be paired
+// together, looking up the decision cached for the candidate squads
+func (i *peopleIndex) pairingAllowed(squad *types.Squad) bool {
+	if allowed, ok := i.pairings[squad]; ok {
+		return allowed
+	}
+	return types.PairingAllowed(squad.People, i.people)
+}
@@ -0,0 +1,173 @@
+package solver
+
+import (
+	"matchmaker/libs/testutils"
+	"matchmaker/libs/types"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// isCompatible returns true if the session can be added to a planning made of
+// the given sessions
+func isCompatible(session *types.ReviewSession, sessions []*types.ReviewSession) bool {
+	return newSearchState(withSession(sessions, session), DefaultConstraints()).withAll(sessions).canAdd(session)
+}
+
+func TestSearchStateWith(t *testing.T) {
+	configMock := testutils.NewConfigMock()
+	configMock.SetupWorkHours()
+	defer configMock.Restore()
+
+	person1 := &types.Person{Email: "person1@example.com", MaxSessionsPerWeek: 2}
+	person2 := &types.Person{Email: "person2@example.com", MaxSessionsPerWeek: 2}
+	person3 := &types.Person{Email: "person3@example.com", MaxSessionsPerWeek: 2}
+
+	start := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	session1 := &types.ReviewSession{
+		Reviewers: &types.Squad{People: []*types.Person{person1, person2}},
+		Range:     &types.Range{Start: start, End: start.Add(time.Hour)},
+	}
+	session2 := &types.ReviewSession{
+		Reviewers: &types.Squad{People: []*types.Person{person1, person3}},
+		Range:     &types.Range{Start: start.AddDate(0, 0, 1), End: start.AddDate(0, 0, 1).Add(time.Hour)},
+	}
+	session3 := &types.ReviewSession{
+		Reviewers: &types.Squad{People: []*types.Person{person1, person3}},
+		Range:     &types.Range{Start: start.AddDate(0, 0, 2), End: start.AddDate(0, 0, 2).Add(time.Hour)},
+	}
+
+	empty := newSearchState([]*types.ReviewSession{session1, session2, session3}, DefaultConstraints())
+	first := empty.with(session1)
+	second := first.with(session2)
+
+	// Adding sessions leaves previous states untouched
+	if empty.size() != 0 || empty.sessionCount(person1) != 0 {
+		t.Errorf("empty state changed: %d sessions, %d for person1", empty.size(), empty.sessionCount(person1))
+	}
+	if first.size() != 1 || first.sessionCount(person1) != 1 || first.sessionCount(person3) != 0 {
+		t.Errorf("first state changed: %d sessions, %d for person1, %d for person3", first.size(), first.sessionCount(person1), first.sessionCount(person3))
+	}
+	if second.sessionCount(person1) != 2 || second.sessionCount(person2) != 1 || second.sessionCount(person3) != 1 {
+		t.Errorf("second state counts = %d, %d, %d, want 2, 1, 1", second.sessionCount(person1), second.sessionCount(person2), second.sessionCount(person3))
+	}
+
+	// person1 has no session left, and person3 is already paired with person1
+	if second.canAdd(session3) {
+		t.Error("canAdd() accepted a session for a person with no session left")
+	}
+	if !first.canAdd(session3) {
+		t.Error("canAdd() rejected a session compatible with the planning")
+	}
+
+	// Sibling states branch independently, also when explored concurrently
+	var waitGroup sync.WaitGroup
+	for _, session := range []*types.ReviewSession{session2, session3} {
+		waitGroup.Add(1)
+		go func(session *types.ReviewSession) {
+			defer waitGroup.Done()
+			branch := first.with(session)
+			if branch.sessionCount(person3) != 1 || branch.canAdd(session) {
+				t.Errorf("branch with session at %v is inconsistent", session.Start())
+			}
+		}(session)
+	}
+	waitGroup.Wait()
+
+	// Unknown people can't attend
+	stranger := &types.ReviewSession{
+		Reviewers: &types.Squad{People: []*types.Person{person2, {Email: "stranger@example.com", MaxSessionsPerWeek: 2}}},
+		Range:     session2.Range,
+	}
+	if empty.canAdd(stranger) {
+		t.Error("canAdd() accepted a session of a person outside the candidate sessions")
+	}
+}
+
+func TestSearchStateTotals(t *testing.T) {
+	configMock := testutils.NewConfigMock()
+	configMock.SetupWorkHours()
+	defer configMock.Restore()
+
+	problem := newTestProblem()
+	start := problem.WorkRanges[0].Start
+	newSession := func(offset time.Duration, people ...*types.Person) *types.ReviewSession {
+		return &types.ReviewSession{
+			Reviewers: &types.Squad{People: people, RepeatPenalty: 0.5, TeamPenalty: 1},
+			Range:     &types.Range{Start: start.Add(offset), End: start.Add(offset + time.Hour)},
+		}
+	}
+	person1, person2, person3 := problem.People[0], problem.People[1], problem.People[2]
+	sessions := []*types.ReviewSession{
+		newSession(0, person1, person2),
+		newSession(30*time.Minute, person1, person3),
+		newSession(4*time.Hour, person2, person3),
+	}
+
+	// Running totals match the ones computed over all sessions
+	objective := newObjective(problem, Options{Parameters: SearchParameters{CoveragePeriodSpan: defaultCoveragePeriodSpan}})
+	state := objective.newState(sessions)
+	for i, session := range sessions {
+		state = state.with(session)
+		planned := sessions[:i+1]
+
+		coverage, maxCoverage := getCoverage(problem.WorkRanges, planned, defaultCoveragePeriodSpan)
+		if missingCoverage := getMissingConverage(coverage, problem.TargetCoverage); state.missingCoverage != missingCoverage || state.maxCoverage != maxCoverage {
+			t.Errorf("after %d sessions: coverage totals = %d, %d, want %d, %d", i+1, state.missingCoverage, state.maxCoverage, missingCoverage, maxCoverage)
+		}
+		if got := state.coverageByPeriod(); !reflect.DeepEqual(got, coverage) {
+			t.Errorf("after %d sessions: coverageByPeriod() = %v, want %v", i+1, got, coverage)
+		}
+		if !reflect.DeepEqual(state.Sessions(), planned) {
+			t.Errorf("after %d sessions: Sessions() = %v, want %v", i+1, state.Sessions(), planned)
+		}
+		if state.repetition != 0.5*float64(i+1) || state.team != float64(i+1) {
+			t.Errorf("after %d sessions: repetition, team = %v, %v", i+1, state.repetition, state.team)
+		}
+	}
+
+	// Counts 2, 2, 2 have no variance, and good reviewers are at their maximum
+	result := objective.evaluateState(state)
+	if result.Fairness != 0 || result.ReviewerLoad != 2 {
+		t.Errorf("evaluateState() fairness, reviewer load = %v, %v, want 0, 2", result.Fairness, result.ReviewerLoad)
+	}
+}
+
+func TestSearchStatePairings(t *testing.T) {
+	configMock := testutils.NewConfigMock()
+	configMock.SetupWorkHours()
+	defer configMock.Restore()
+
+	newcomer := &types.Person{Email: "newcomer@example.com", MaxSessionsPerWeek: 2, PairWith: []string{"buddy@example.com"}}
+	buddy := &types.Person{Email: "buddy@example.com", MaxSessionsPerWeek: 2}
+	other := &types.Person{Email: "other@example.com", MaxSessionsPerWeek: 2, Avoid: []string{"buddy@example.com"}}
+
+	start := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	newSession := func(day int, people ...*types.Person) *types.ReviewSession {
+		return &types.ReviewSession{
+			Reviewers: &types.Squad{People: people},
+			Range:     &types.Range{Start: start.AddDate(0, 0, day), End: start.AddDate(0, 0, day).Add(time.Hour)},
+		}
+	}
+	withBuddy := newSession(0, newcomer, buddy)
+	withOther := newSession(1, newcomer, other)
+	avoided := newSession(2, buddy, other)
+
+	state := newSearchState([]*types.ReviewSession{withBuddy, withOther, avoided}, DefaultConstraints())
+	if len(state.index.pairings) != 3 {
+		t.Errorf("pairings cached for %d squads, want 3", len(state.index.pairings))
+	}
+	for _, test := range []struct {
+		session *types.ReviewSession
+		want    bool
+	}{
+		{withBuddy, true},
+		{withOther, false},
+		{avoided, false},
+	} {
+		if got := state.canAdd(test.session); got != test.want {
+			t.Errorf("canAdd(%v) = %v, want %v", test.session.Reviewers.People, got, test.want)
+		}
+	}
+}
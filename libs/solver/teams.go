@@ -0,0 +1,81 @@
+package solver
+
+import (
+	"matchmaker/libs/config"
+	"matchmaker/libs/types"
+)
+
+// TeamPolicy controls how people of the same team are paired. People without
+// a team can be paired with anyone.
+type TeamPolicy struct {
+	// Mode is one of the config.TeamPolicy values, config.TeamPolicyAny if empty
+	Mode string
+
+	// ByDepartment compares the departments of people instead of their teams
+	ByDepartment bool
+}
+
+// team returns the team or department of the person compared by the policy
+func (p TeamPolicy) team(person *types.Person) string {
+	if p.ByDepartment {
+		return person.Department
+	}
+	return person.Team
+}
+
+// sameTeamShare returns the share of the pairs of people with a known team
+// that are of the same team, 0 if there is no such pair
+func (p TeamPolicy) sameTeamShare(people []*types.Person) float64 {
+	pairs, sameTeam := 0, 0
+	for i := range people {
+		for j := i + 1; j < len(people); j++ {
+			team1, team2 := p.team(people[i]), p.team(people[j])
+			if team1 == "" || team2 == "" {
+				continue
+			}
+			pairs++
+			if team1 == team2 {
+				sameTeam++
+			}
+		}
+	}
+	if pairs == 0 {
+		return 0
+	}
+	return float64(sameTeam) / float64(pairs)
+}
+
+// Penalty measures how much the people stay within their teams when the
+// policy prefers cross-team squads, from 0 to 1 when they are all of the same
+// team
+func (p TeamPolicy) Penalty(people []*types.Person) float64 {
+	if p.Mode != config.TeamPolicyPreferCross {
+		return 0
+	}
+	return p.sameTeamShare(people)
+}
+
+// Allows returns false if the people can't be paired under the policy: two of
+// them are of the same team when cross-team squads are required, or of
+// different teams when same-team squads are
+func (p TeamPolicy) Allows(people []*types.Person) bool {
+	switch p.Mode {
+	case config.TeamPolicyRequireCross:
+		return p.sameTeamShare(people) == 0
+	case config.TeamPolicySameTeam:
+		return p.sameTeamShare(people) == 1 || !p.hasTeamPairs(people)
+	default:
+		return true
+	}
+}
+
+// hasTeamPairs returns true if at least two of the people have a known team
+func (p TeamPolicy) hasTeamPairs(people []*types.Person) bool {
+	known := 0
+	for _, person := range people {
+		if p.team(person) != "" {
+			known++
+		}
+	}
+	return known >= 2
+}
@@ -0,0 +1,70 @@
+package solver
+
+import (
+	"matchmaker/libs/config"
+	"matchmaker/libs/types"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTeamPolicy(t *testing.T) {
+	alice := &types.Person{Email: "alice@example.com", Team: "payments", Department: "engineering"}
+	bob := &types.Person{Email: "bob@example.com", Team: "payments", Department: "engineering"}
+	carol := &types.Person{Email: "carol@example.com", Team: "search", Department: "engineering"}
+	dave := &types.Person{Email: "dave@example.com", Team: "growth", Department: "marketing"}
+	newcomer := &types.Person{Email: "newcomer@example.com"}
+
+	tests := []struct {
+		name    string
+		policy  TeamPolicy
+		people  []*types.Person
+		allows  bool
+		penalty float64
+	}{
+		{"any policy", TeamPolicy{}, []*types.Person{alice, bob}, true, 0},
+		{"prefer cross with the same team", TeamPolicy{Mode: config.TeamPolicyPreferCross}, []*types.Person{alice, bob}, true, 1},
+		{"prefer cross in a partly mixed trio", TeamPolicy{Mode: config.TeamPolicyPreferCross}, []*types.Person{alice, bob, carol}, true, 1.0 / 3},
+		{"require cross with the same team", TeamPolicy{Mode: config.TeamPolicyRequireCross}, []*types.Person{alice, bob}, false, 0},
+		{"require cross with other teams", TeamPolicy{Mode: config.TeamPolicyRequireCross}, []*types.Person{alice, carol}, true, 0},
+		{"require cross by department", TeamPolicy{Mode: config.TeamPolicyRequireCross, ByDepartment: true}, []*types.Person{alice, carol}, false, 0},
+		{"require cross without a team", TeamPolicy{Mode: config.TeamPolicyRequireCross}, []*types.Person{alice, newcomer}, true, 0},
+		{"same team", TeamPolicy{Mode: config.TeamPolicySameTeam}, []*types.Person{alice, bob}, true, 0},
+		{"same team with another team", TeamPolicy{Mode: config.TeamPolicySameTeam}, []*types.Person{alice, dave}, false, 0},
+		{"same team without a team", TeamPolicy{Mode: config.TeamPolicySameTeam}, []*types.Person{dave, newcomer}, true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Allows(tt.people); got != tt.allows {
+				t.Errorf("Allows() = %v, want %v", got, tt.allows)
+			}
+			if got := tt.policy.Penalty(tt.people); got != tt.penalty {
+				t.Errorf("Penalty() = %v, want %v", got, tt.penalty)
+			}
+		})
+	}
+}
+
+func TestGenerateSquadsTeams(t *testing.T) {
+	alice := &types.Person{Email: "alice@example.com", IsGoodReviewer: true, Team: "payments"}
+	bob := &types.Person{Email: "bob@example.com", Team: "payments"}
+	carol := &types.Person{Email: "carol@example.com", Team: "search"}
+	people := []*types.Person{alice, bob, carol}
+
+	// Required cross-team squads leave alice & bob out
+	squads := generateSquads(people, []*types.BusyTime{}, 2, rand.New(rand.NewSource(1)), RepeatPolicy{}, time.Time{}, DefaultSeniorityRules(), TeamPolicy{Mode: config.TeamPolicyRequireCross})
+	if len(squads) != 1 || squads[0].GetDisplayName() != "alice & carol" {
+		t.Errorf("generateSquads() with required cross-team squads returned %d squads, want only alice & carol", len(squads))
+	}
+
+	// Preferred cross-team squads are kept with a penalty
+	squads = generateSquads(people, []*types.BusyTime{}, 2, rand.New(rand.NewSource(1)), RepeatPolicy{}, time.Time{}, DefaultSeniorityRules(), TeamPolicy{Mode: config.TeamPolicyPreferCross})
+	penalties := map[string]float64{}
+	for _, squad := range squads {
+		penalties[squad.GetDisplayName()] = squad.TeamPenalty
+	}
+	if want := map[string]float64{"alice & bob": 1, "alice & carol": 0}; !reflect.DeepEqual(penalties, want) {
+		t.Errorf("generateSquads() team penalties = %v, want %v", penalties, want)
+	}
+}
@@ -4,9 +4,7 @@ import (
 	"matchmaker/libs/config"
 	"matchmaker/libs/types"
 	"matchmaker/libs/util"
-	"time"
-
-	"github.com/spf13/viper"
+	"math/rand"
 )
 
 // WeeklySolveResult contains the result of the weekly solve operation
@@ -16,18 +14,23 @@ type WeeklySolveResult struct {
 	UnmatchedTuples []types.Tuple
 }
 
-// WeeklySolve finds a single session for a tuple of people in a specific week
-func WeeklySolve(problem *types.Problem) *WeeklySolveResult {
+// WeeklySolve finds a single session for a pair or trio of people in a specific week,
+// keeping the people within their daily limit given the sessions already
+// planned. The random generator breaks ties between equally scored sessions.
+func WeeklySolve(problem *types.Problem, planned []*types.ReviewSession, r *rand.Rand) *WeeklySolveResult {
 	// Generate squads for the tuple
 	squads := generateSquadsForTuple(problem.People, problem.BusyTimes)
 
 	// Generate time ranges for the work ranges
 	sessionDuration := config.GetSessionDuration()
-	ranges := types.GenerateTimeRanges(problem.WorkRanges, sessionDuration)
+	ranges := types.GenerateTimeRanges(problem.WorkRanges, sessionDuration, r)
 
 	// Generate possible sessions
 	sessions := types.GenerateSessions(squads, ranges)
 
+	dailyLimit := dailyLimitConstraint{limit: config.GetMaxSessionsPerPersonPerDay()}
+	scorer := newSlotScorer()
+
 	// Find the best session (we only need one)
 	var bestSession *types.ReviewSession
 	var bestScore int
@@ -35,10 +38,11 @@ func WeeklySolve(problem *types.Problem) *WeeklySolveResult {
 
 	for _, session := range sessions {
 		// Score the session based on how well it fits
-		score, isValid := scoreSession(session)
+		score, isValid := scoreSession(session, scorer)
 
-		// Skip invalid sessions
-		if !isValid {
+		// Skip invalid sessions, and sessions on days already full for one of
+		// the people
+		if !isValid || dailyLimit.Violation(sessionList(planned), session) > 0 {
 			continue
 		}
 
@@ -70,12 +74,15 @@ func WeeklySolve(problem *types.Problem) *WeeklySolveResult {
 	}
 
 	// If no session was found, add the tuple to unmatched tuples
-	if bestSession == nil && len(problem.People) == 2 {
-		// Create a tuple from the two people
+	if bestSession == nil && len(squads) > 0 {
+		// Create a tuple from the people
 		tuple := types.Tuple{
 			Person1: problem.People[0],
 			Person2: problem.People[1],
 		}
+		if len(problem.People) == 3 {
+			tuple.Person3 = problem.People[2]
+		}
 		result.UnmatchedTuples = append(result.UnmatchedTuples, tuple)
 	}
 
@@ -84,9 +91,9 @@ func WeeklySolve(problem *types.Problem) *WeeklySolveResult {
 
 // generateSquadsForTuple creates squads for a specific tuple of people
 func generateSquadsForTuple(people []*types.Person, busyTimes []*types.BusyTime) []*types.Squad {
-	// For a tuple, we only need one squad with both people
-	if len(people) != 2 {
-		util.LogInfo("Warning: WeeklySolve expects exactly 2 people per tuple", map[string]interface{}{
+	// For a tuple, we only need one squad with all its people
+	if len(people) != 2 && len(people) != 3 {
+		util.LogInfo("Warning: WeeklySolve expects 2 or 3 people per tuple", map[string]interface{}{
 			"peopleCount": len(people),
 		})
 		return []*types.Squad{}
@@ -100,27 +107,23 @@ func generateSquadsForTuple(people []*types.Person, busyTimes []*types.BusyTime)
 	return []*types.Squad{squad}
 }
 
-// scoreSession assigns a score to a session based on how well it fits
-// Returns (score, isValid) where isValid indicates if the session is valid
-func scoreSession(session *types.ReviewSession) (int, bool) {
+// scoreSession assigns a score to a session based on how well it fits, rated
+// by the slot scorer. Returns (score, isValid) where isValid indicates if the
+// session is valid
+func scoreSession(session *types.ReviewSession, scorer slotScorer) (int, bool) {
 	// Check if the session conflicts with any busy times in the squad
 	if hasTimeConflict(session) {
 		return 0, false // Invalid session
 	}
 
-	// Get session start time
-	startTime := session.Start()
-
-	// Get working hours from configuration
-	workingHours := getWorkingHours(startTime)
-
-	// Calculate score based on time preferences
-	score := calculateTimeScore(startTime, workingHours)
-
-	// Add day of week score
-	score += calculateDayScore(startTime)
+	// Check if a person of the squad has no sessions on that day
+	for _, person := range session.Reviewers.People {
+		if person.IsBlockedOn(session.Start()) {
+			return 0, false
+		}
+	}
 
-	return score, true
+	return scorer.score(session), true
 }
 
 // hasTimeConflict checks if a session conflicts with any busy times in its squad
@@ -133,150 +136,19 @@ func hasTimeConflict(session *types.ReviewSession) bool {
 	return false
 }
 
-// WorkingHours represents the configured working hours
-type WorkingHours struct {
-	MorningStart   time.Time
-	MorningEnd     time.Time
-	AfternoonStart time.Time
-	AfternoonEnd   time.Time
-	LunchStart     time.Time
-	LunchEnd       time.Time
-	LastHourStart  time.Time
-}
-
-// getWorkingHours retrieves working hours from configuration
-func getWorkingHours(referenceTime time.Time) WorkingHours {
-	// Get working hours from configuration
-	morningStartHour := viper.GetInt("workingHours.morning.start.hour")
-	morningStartMinute := viper.GetInt("workingHours.morning.start.minute")
-	morningEndHour := viper.GetInt("workingHours.morning.end.hour")
-	morningEndMinute := viper.GetInt("workingHours.morning.end.minute")
-
-	afternoonStartHour := viper.GetInt("workingHours.afternoon.start.hour")
-	afternoonStartMinute := viper.GetInt("workingHours.afternoon.start.minute")
-	afternoonEndHour := viper.GetInt("workingHours.afternoon.end.hour")
-	afternoonEndMinute := viper.GetInt("workingHours.afternoon.end.minute")
-
-	// Create time objects for comparison
-	morningStart := time.Date(
-		referenceTime.Year(), referenceTime.Month(), referenceTime.Day(),
-		morningStartHour, morningStartMinute, 0, 0,
-		referenceTime.Location(),
-	)
-
-	morningEnd := time.Date(
-		referenceTime.Year(), referenceTime.Month(), referenceTime.Day(),
-		morningEndHour, morningEndMinute, 0, 0,
-		referenceTime.Location(),
-	)
-
-	afternoonStart := time.Date(
-		referenceTime.Year(), referenceTime.Month(), referenceTime.Day(),
-		afternoonStartHour, afternoonStartMinute, 0, 0,
-		referenceTime.Location(),
-	)
-
-	afternoonEnd := time.Date(
-		referenceTime.Year(), referenceTime.Month(), referenceTime.Day(),
-		afternoonEndHour, afternoonEndMinute, 0, 0,
-		referenceTime.Location(),
-	)
-
-	// Define lunch break using the end of morning and start of afternoon
-	lunchStart := morningEnd
-	lunchEnd := afternoonStart
-
-	// Last hour of the day (to avoid)
-	lastHourStart := time.Date(
-		referenceTime.Year(), referenceTime.Month(), referenceTime.Day(),
-		afternoonEndHour-1, afternoonEndMinute, 0, 0,
-		referenceTime.Location(),
-	)
-
-	return WorkingHours{
-		MorningStart:   morningStart,
-		MorningEnd:     morningEnd,
-		AfternoonStart: afternoonStart,
-		AfternoonEnd:   afternoonEnd,
-		LunchStart:     lunchStart,
-		LunchEnd:       lunchEnd,
-		LastHourStart:  lastHourStart,
-	}
-}
-
-// isInRange checks if a time is within a range (inclusive)
-func isInRange(t, start, end time.Time) bool {
-	return !t.Before(start) && !t.After(end)
-}
-
-// calculateTimeScore calculates the score based on time of day
-func calculateTimeScore(startTime time.Time, hours WorkingHours) int {
-	score := 0
-
-	// Check if session is in the last hour of the day (low energy)
-	if isInRange(startTime, hours.LastHourStart, hours.AfternoonEnd) {
-		score -= 20 // Strong penalty for sessions in the last hour
-	}
-
-	// Define preferred time slots
-	preferredMorningStart := hours.LunchStart.Add(-1 * time.Hour)
-	preferredAfternoonEnd := hours.LunchEnd.Add(1 * time.Hour)
-
-	// Check if session is in preferred morning slot (1 hour before lunch)
-	if isInRange(startTime, preferredMorningStart, hours.LunchStart) {
-		score += 25 // Highest score for sessions just before lunch
-	}
-
-	// Check if session is in preferred afternoon slot (1 hour after lunch)
-	if isInRange(startTime, hours.LunchEnd, preferredAfternoonEnd) {
-		score += 25 // Highest score for sessions just after lunch
-	}
-
-	// Check if session is in regular morning slot
-	if isInRange(startTime, hours.MorningStart, hours.MorningEnd) {
-		score += 10 // Lower score for other morning sessions
-	}
-
-	// Check if session is in regular afternoon slot
-	if isInRange(startTime, hours.AfternoonStart, hours.AfternoonEnd) {
-		score += 10 // Lower score for other afternoon sessions
-	}
-
-	return score
-}
-
-// calculateDayScore calculates the score based on day of week
-func calculateDayScore(startTime time.Time) int {
-	score := 0
-
-	// Avoid Mondays and Fridays
-	weekday := startTime.Weekday()
-	if weekday == time.Monday || weekday == time.Friday {
-		score -= 15 // Penalty for Mondays and Fridays
-	} else {
-		score += 5 // Bonus for other weekdays
-	}
-
-	// Prefer sessions in the middle of the week (Tuesday-Thursday)
-	if weekday == time.Tuesday || weekday == time.Wednesday || weekday == time.Thursday {
-		score += 10 // Additional bonus for middle of the week
-	}
-
-	return score
-}
-
-// FindSessionForTuple finds a session for a tuple of people in a specific week
-func FindSessionForTuple(tuple types.Tuple, workRanges []*types.Range, busyTimes []*types.BusyTime) *types.ReviewSession {
+// FindSessionForTuple finds a session for a tuple of people in a specific week,
+// given the sessions already planned
+func FindSessionForTuple(tuple types.Tuple, workRanges []*types.Range, busyTimes []*types.BusyTime, planned []*types.ReviewSession, r *rand.Rand) *types.ReviewSession {
 	// Create a problem for the tuple
 	problem := &types.Problem{
-		People:         []*types.Person{tuple.Person1, tuple.Person2},
+		People:         tuple.People(),
 		WorkRanges:     workRanges,
 		BusyTimes:      busyTimes,
 		TargetCoverage: 0,
 	}
 
 	// Find a session using the weekly solver
-	result := WeeklySolve(problem)
+	result := WeeklySolve(problem, planned, r)
 	if len(result.Solution.Sessions) > 0 {
 		return result.Solution.Sessions[0]
 	}
@@ -3,6 +3,7 @@ package solver
 import (
 	"matchmaker/libs/testutils"
 	"matchmaker/libs/types"
+	"math/rand"
 	"testing"
 	"time"
 
@@ -33,7 +34,7 @@ func TestWeeklySolve(t *testing.T) {
 	}
 
 	// Test with no busy times
-	result := WeeklySolve(problem)
+	result := WeeklySolve(problem, nil, rand.New(rand.NewSource(1)))
 
 	// Verify that we got a solution
 	if result.Solution == nil {
@@ -61,7 +62,7 @@ func TestWeeklySolve(t *testing.T) {
 	}
 	problem.BusyTimes = []*types.BusyTime{busyTime}
 
-	result = WeeklySolve(problem)
+	result = WeeklySolve(problem, nil, rand.New(rand.NewSource(1)))
 
 	// Verify that the session doesn't conflict with busy time
 	session = result.Solution.Sessions[0]
@@ -70,6 +71,101 @@ func TestWeeklySolve(t *testing.T) {
 	}
 }
 
+func TestWeeklySolveTimezones(t *testing.T) {
+	configMock := testutils.NewConfigMock()
+	configMock.SetupWorkHours()
+	defer configMock.Restore()
+
+	// 09:00-12:00 in Montreal is 13:00-16:00 UTC, overlapping the Paris
+	// working hours from 13:00 to 15:00 UTC
+	paris := &types.Person{Email: "paris@example.com", MaxSessionsPerWeek: 2, Timezone: "Europe/Paris", WorkingHours: []string{"09:00-17:00"}}
+	montreal := &types.Person{Email: "montreal@example.com", MaxSessionsPerWeek: 2, Timezone: "America/Montreal", WorkingHours: []string{"09:00-12:00"}}
+
+	start := time.Date(2024, 4, 2, 6, 0, 0, 0, time.UTC)
+	problem := &types.Problem{
+		People:     []*types.Person{paris, montreal},
+		WorkRanges: []*types.Range{{Start: start, End: start.Add(12 * time.Hour)}},
+	}
+
+	result := WeeklySolve(problem, nil, rand.New(rand.NewSource(1)))
+	if len(result.Solution.Sessions) != 1 {
+		t.Fatalf("WeeklySolve() returned %d sessions, want 1", len(result.Solution.Sessions))
+	}
+	session := result.Solution.Sessions[0]
+	if !paris.IsWorkingDuring(session.Range) || !montreal.IsWorkingDuring(session.Range) {
+		t.Errorf("WeeklySolve() planned a session at %v, outside the shared working hours", session.Start())
+	}
+}
+
+func TestFindSessionForTrio(t *testing.T) {
+	configMock := testutils.NewConfigMock()
+	configMock.SetupWorkHours()
+	defer configMock.Restore()
+
+	person1 := &types.Person{Email: "person1@example.com", MaxSessionsPerWeek: 2}
+	person2 := &types.Person{Email: "person2@example.com", MaxSessionsPerWeek: 2}
+	person3 := &types.Person{Email: "person3@example.com", MaxSessionsPerWeek: 2}
+	trio := types.Tuple{Person1: person1, Person2: person2, Person3: person3}
+
+	// The pair is free all day, the third person only in the last hour
+	start := time.Date(2024, 4, 2, 9, 0, 0, 0, time.UTC)
+	workRanges := []*types.Range{{Start: start, End: start.Add(8 * time.Hour)}}
+	busyTimes := []*types.BusyTime{{
+		Person: person3,
+		Range:  &types.Range{Start: start, End: start.Add(7 * time.Hour)},
+	}}
+
+	session := FindSessionForTuple(trio, workRanges, busyTimes, nil, rand.New(rand.NewSource(1)))
+	if session == nil {
+		t.Fatal("FindSessionForTuple() found no session for the trio")
+	}
+	if len(session.Reviewers.People) != 3 {
+		t.Errorf("FindSessionForTuple() session has %d people, want 3", len(session.Reviewers.People))
+	}
+	if !session.Start().Equal(start.Add(7 * time.Hour)) {
+		t.Errorf("FindSessionForTuple() planned the trio at %v, want the free slot of the third person", session.Start())
+	}
+
+	// No common free slot
+	busyTimes[0].Range.End = workRanges[0].End
+	result := WeeklySolve(&types.Problem{People: trio.People(), WorkRanges: workRanges, BusyTimes: busyTimes}, nil, rand.New(rand.NewSource(1)))
+	if len(result.Solution.Sessions) != 0 {
+		t.Errorf("WeeklySolve() returned %d sessions for a busy trio, want 0", len(result.Solution.Sessions))
+	}
+	if len(result.UnmatchedTuples) != 1 || result.UnmatchedTuples[0].Person3 != person3 {
+		t.Errorf("WeeklySolve() unmatched tuples = %v, want the trio", result.UnmatchedTuples)
+	}
+}
+
+func TestWeeklySolveDailyLimit(t *testing.T) {
+	configMock := testutils.NewConfigMock()
+	configMock.SetupWorkHours()
+	defer configMock.Restore()
+
+	person1 := &types.Person{Email: "person1@example.com", MaxSessionsPerWeek: 2, MaxSessionsPerDay: 1}
+	person2 := &types.Person{Email: "person2@example.com", MaxSessionsPerWeek: 2}
+	person3 := &types.Person{Email: "person3@example.com", MaxSessionsPerWeek: 2}
+
+	start := time.Date(2024, 4, 2, 9, 0, 0, 0, time.UTC)
+	problem := &types.Problem{
+		People:     []*types.Person{person1, person2},
+		WorkRanges: []*types.Range{{Start: start, End: start.Add(8 * time.Hour)}},
+	}
+	planned := []*types.ReviewSession{{
+		Reviewers: &types.Squad{People: []*types.Person{person1, person3}},
+		Range:     &types.Range{Start: start, End: start.Add(time.Hour)},
+	}}
+
+	// person1 already has their only session of the day
+	result := WeeklySolve(problem, planned, rand.New(rand.NewSource(1)))
+	if len(result.Solution.Sessions) != 0 {
+		t.Errorf("WeeklySolve() returned %d sessions beyond the daily limit, want 0", len(result.Solution.Sessions))
+	}
+	if len(result.UnmatchedTuples) != 1 {
+		t.Errorf("WeeklySolve() returned %d unmatched tuples, want 1", len(result.UnmatchedTuples))
+	}
+}
+
 func TestGenerateSquadsForTuple(t *testing.T) {
 	// Create test persons
 	person1 := &types.Person{Email: "person1@example.com", MaxSessionsPerWeek: 2}
@@ -136,7 +232,7 @@ func TestScoreSession(t *testing.T) {
 		Range:     &types.Range{Start: start, End: start.Add(time.Hour)},
 	}
 
-	score, isValid := scoreSession(session)
+	score, isValid := scoreSession(session, newSlotScorer())
 	if !isValid {
 		t.Error("scoreSession() returned invalid for valid session")
 	}
@@ -154,7 +250,7 @@ func TestScoreSession(t *testing.T) {
 		Range:     &types.Range{Start: start, End: start.Add(time.Hour)},
 	}
 
-	score, isValid = scoreSession(session)
+	score, isValid = scoreSession(session, newSlotScorer())
 	if !isValid {
 		t.Error("scoreSession() returned invalid for valid session")
 	}
@@ -172,7 +268,7 @@ func TestScoreSession(t *testing.T) {
 		Range:     &types.Range{Start: start, End: start.Add(time.Hour)},
 	}
 
-	score, isValid = scoreSession(session)
+	score, isValid = scoreSession(session, newSlotScorer())
 	if !isValid {
 		t.Error("scoreSession() returned invalid for valid session")
 	}
@@ -190,7 +286,7 @@ func TestScoreSession(t *testing.T) {
 		Range:     &types.Range{Start: start, End: start.Add(time.Hour)},
 	}
 
-	score, isValid = scoreSession(session)
+	score, isValid = scoreSession(session, newSlotScorer())
 	if !isValid {
 		t.Error("scoreSession() returned invalid for valid session")
 	}
@@ -208,7 +304,7 @@ func TestScoreSession(t *testing.T) {
 		Range:     &types.Range{Start: start, End: start.Add(time.Hour)},
 	}
 
-	score, isValid = scoreSession(session)
+	score, isValid = scoreSession(session, newSlotScorer())
 	if !isValid {
 		t.Error("scoreSession() returned invalid for valid session")
 	}
@@ -224,7 +320,7 @@ func TestScoreSession(t *testing.T) {
 	}
 	squad.BusyRanges = []*types.Range{busyRange}
 
-	_, isValid = scoreSession(session)
+	_, isValid = scoreSession(session, newSlotScorer())
 	if isValid {
 		t.Error("scoreSession() returned valid for session with time conflict")
 	}
@@ -266,146 +362,32 @@ func TestHasTimeConflict(t *testing.T) {
 	}
 }
 
-func TestCalculateTimeScore(t *testing.T) {
-	// Create a config mock
+func TestScoreSessionPreferences(t *testing.T) {
 	configMock := testutils.NewConfigMock()
 	configMock.SetupWorkHours()
 	defer configMock.Restore()
 
-	// Get working hours for a reference time
-	referenceTime := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
-	hours := getWorkingHours(referenceTime)
-
-	// Print working hours for debugging
-	t.Logf("Working hours: Morning %s-%s, Afternoon %s-%s, Lunch %s-%s, Last hour starts at %s",
-		hours.MorningStart.Format("15:04"),
-		hours.MorningEnd.Format("15:04"),
-		hours.AfternoonStart.Format("15:04"),
-		hours.AfternoonEnd.Format("15:04"),
-		hours.LunchStart.Format("15:04"),
-		hours.LunchEnd.Format("15:04"),
-		hours.LastHourStart.Format("15:04"),
-	)
-
-	// Test with session at the start of preferred morning slot (1 hour before lunch)
-	// Default morning hours: 9:00-12:00, lunch at 12:00
-	// This time matches both preferred morning slot (+25) and morning slot (+10)
-	start := hours.LunchStart.Add(-1 * time.Hour) // 11:00
-	score := calculateTimeScore(start, hours)
-	t.Logf("Test 1: Time %s, Score %d", start.Format("15:04"), score)
-	if score != 35 {
-		t.Errorf("calculateTimeScore() returned score %d for start of preferred morning slot, want 35 (25 + 10)", score)
-	}
-
-	// Test with session at the end of preferred morning slot (lunch start)
-	start = hours.LunchStart // 12:00
-	score = calculateTimeScore(start, hours)
-	t.Logf("Test 2: Time %s, Score %d", start.Format("15:04"), score)
-	if score != 35 {
-		t.Errorf("calculateTimeScore() returned score %d for end of preferred morning slot, want 35 (25 + 10)", score)
-	}
-
-	// Test with session at the start of preferred afternoon slot (lunch end)
-	// Default afternoon hours: 13:00-17:00, lunch ends at 13:00
-	// This time matches both preferred afternoon slot (+25) and afternoon slot (+10)
-	start = hours.LunchEnd // 13:00
-	score = calculateTimeScore(start, hours)
-	t.Logf("Test 3: Time %s, Score %d", start.Format("15:04"), score)
-	if score != 35 {
-		t.Errorf("calculateTimeScore() returned score %d for start of preferred afternoon slot, want 35 (25 + 10)", score)
-	}
-
-	// Test with session at the end of preferred afternoon slot
-	start = hours.LunchEnd.Add(1 * time.Hour) // 14:00
-	score = calculateTimeScore(start, hours)
-	t.Logf("Test 4: Time %s, Score %d", start.Format("15:04"), score)
-	if score != 35 {
-		t.Errorf("calculateTimeScore() returned score %d for end of preferred afternoon slot, want 35 (25 + 10)", score)
-	}
-
-	// Test with session at the start of last hour
-	// Default afternoon hours: 13:00-17:00, last hour starts at 16:00
-	// This time matches both last hour (-20) and afternoon slot (+10)
-	start = hours.LastHourStart // 16:00
-	score = calculateTimeScore(start, hours)
-	t.Logf("Test 5: Time %s, Score %d", start.Format("15:04"), score)
-	if score != -10 {
-		t.Errorf("calculateTimeScore() returned score %d for start of last hour, want -10 (-20 + 10)", score)
-	}
-
-	// Test with session at the end of last hour
-	start = hours.AfternoonEnd // 17:00
-	score = calculateTimeScore(start, hours)
-	t.Logf("Test 6: Time %s, Score %d", start.Format("15:04"), score)
-	if score != -10 {
-		t.Errorf("calculateTimeScore() returned score %d for end of last hour, want -10 (-20 + 10)", score)
-	}
-
-	// Test with session at the start of regular morning slot
-	// Default morning hours: 9:00-12:00
-	// This time only matches morning slot (+10)
-	start = hours.MorningStart.Add(30 * time.Minute) // 9:30
-	score = calculateTimeScore(start, hours)
-	t.Logf("Test 7: Time %s, Score %d", start.Format("15:04"), score)
-	if score != 10 {
-		t.Errorf("calculateTimeScore() returned score %d for regular morning slot, want 10", score)
-	}
-
-	// Test with session at the end of regular morning slot
-	// Use 11:00 which is before the preferred slot starts
-	start = hours.LunchStart.Add(-2 * time.Hour) // 10:00
-	score = calculateTimeScore(start, hours)
-	t.Logf("Test 8: Time %s, Score %d", start.Format("15:04"), score)
-	if score != 10 {
-		t.Errorf("calculateTimeScore() returned score %d for regular morning slot, want 10", score)
-	}
-
-	// Test with session at the start of regular afternoon slot
-	// Use 15:00 which is after the preferred slot ends
-	start = hours.LunchEnd.Add(2 * time.Hour) // 15:00
-	score = calculateTimeScore(start, hours)
-	t.Logf("Test 9: Time %s, Score %d", start.Format("15:04"), score)
-	if score != 10 {
-		t.Errorf("calculateTimeScore() returned score %d for regular afternoon slot, want 10", score)
-	}
-
-	// Test with session at the end of regular afternoon slot
-	// Use 15:30 which is before the last hour starts
-	start = hours.LastHourStart.Add(-30 * time.Minute) // 15:30
-	score = calculateTimeScore(start, hours)
-	t.Logf("Test 10: Time %s, Score %d", start.Format("15:04"), score)
-	if score != 10 {
-		t.Errorf("calculateTimeScore() returned score %d for regular afternoon slot, want 10", score)
-	}
-
-	// Test with session outside working hours (should get no score)
-	start = hours.MorningStart.Add(-time.Hour) // 8:00
-	score = calculateTimeScore(start, hours)
-	t.Logf("Test 11: Time %s, Score %d", start.Format("15:04"), score)
-	if score != 0 {
-		t.Errorf("calculateTimeScore() returned score %d for outside working hours, want 0", score)
-	}
-}
+	person1 := &types.Person{Email: "person1@example.com", MaxSessionsPerWeek: 2, BlockedDays: []string{"Wednesday"}}
+	person2 := &types.Person{Email: "person2@example.com", MaxSessionsPerWeek: 2, PreferredDays: []string{"Thursday"}, PreferredHours: []string{"14:00-17:00"}}
+	squad := &types.Squad{People: []*types.Person{person1, person2}}
 
-func TestCalculateDayScore(t *testing.T) {
-	// Test with Monday
-	start := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC) // Monday
-	score := calculateDayScore(start)
-	if score > -15 {
-		t.Errorf("calculateDayScore() returned score %d for Monday, want <= -15", score)
+	// Wednesday is blocked by person1
+	start := time.Date(2024, 4, 3, 11, 0, 0, 0, time.UTC)
+	if _, isValid := scoreSession(&types.ReviewSession{Reviewers: squad, Range: &types.Range{Start: start, End: start.Add(time.Hour)}}, newSlotScorer()); isValid {
+		t.Error("scoreSession() returned valid for a session on a blocked day")
 	}
 
-	// Test with Friday
-	start = time.Date(2024, 4, 5, 9, 0, 0, 0, time.UTC) // Friday
-	score = calculateDayScore(start)
-	if score > -15 {
-		t.Errorf("calculateDayScore() returned score %d for Friday, want <= -15", score)
+	// Tuesday at 13:00 misses both preferences of person2: 50 - 2*30
+	start = time.Date(2024, 4, 2, 13, 0, 0, 0, time.UTC)
+	score, isValid := scoreSession(&types.ReviewSession{Reviewers: squad, Range: &types.Range{Start: start, End: start.Add(time.Hour)}}, newSlotScorer())
+	if !isValid || score != -10 {
+		t.Errorf("scoreSession() = %d, %v for a session outside preferences, want -10, true", score, isValid)
 	}
 
-	// Test with Wednesday
-	start = time.Date(2024, 4, 3, 9, 0, 0, 0, time.UTC) // Wednesday
-	score = calculateDayScore(start)
-	if score < 5 {
-		t.Errorf("calculateDayScore() returned score %d for Wednesday, want >= 5", score)
+	// Thursday at 15:00 matches them: 10 (afternoon) + 5 + 10
+	start = time.Date(2024, 4, 4, 15, 0, 0, 0, time.UTC)
+	score, isValid = scoreSession(&types.ReviewSession{Reviewers: squad, Range: &types.Range{Start: start, End: start.Add(time.Hour)}}, newSlotScorer())
+	if !isValid || score != 25 {
+		t.Errorf("scoreSession() = %d, %v for a session within preferences, want 25, true", score, isValid)
 	}
 }
@@ -18,4 +18,5 @@ type Event struct {
 	Organizer string    `json:"organizer"`
 	StartTime time.Time `json:"start_time"`
 	EndTime   time.Time `json:"end_time"`
+	Attendees []string  `json:"attendees,omitempty"`
 }
@@ -0,0 +1,133 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Pairing records people who shared a session
+type Pairing struct {
+	People  []string  `json:"people"`
+	Date    time.Time `json:"date"`
+	BatchID string    `json:"batch_id,omitempty"`
+	Source  string    `json:"source,omitempty"`
+}
+
+// PairingHistory is the list of past pairings, used to avoid pairing the same
+// people again too soon
+type PairingHistory struct {
+	Pairings []Pairing `json:"pairings"`
+}
+
+// LoadPairingHistory reads a pairing history file. A missing file is an empty history.
+func LoadPairingHistory(path string) (*PairingHistory, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &PairingHistory{Pairings: []Pairing{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var history PairingHistory
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, err
+	}
+	return &history, nil
+}
+
+// Save writes the pairing history to a file, creating its directory if needed
+func (h *PairingHistory) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Add records that the people with the given emails shared a session on the
+// given date. It returns false if the same pairing was already recorded.
+func (h *PairingHistory) Add(emails []string, date time.Time, batchID string, source string) bool {
+	people := make([]string, len(emails))
+	copy(people, emails)
+	sort.Strings(people)
+
+	for _, pairing := range h.Pairings {
+		if pairing.Date.Equal(date) && strings.Join(pairing.People, ",") == strings.Join(people, ",") {
+			return false
+		}
+	}
+
+	h.Pairings = append(h.Pairings, Pairing{
+		People:  people,
+		Date:    date,
+		BatchID: batchID,
+		Source:  source,
+	})
+	return true
+}
+
+// AddSession records the people of a review session
+func (h *PairingHistory) AddSession(session *ReviewSession, batchID string, source string) bool {
+	return h.Add(session.Reviewers.Emails(), session.Start(), batchID, source)
+}
+
+// RemoveBatch removes the pairings recorded for a batch and returns how many were removed
+func (h *PairingHistory) RemoveBatch(batchID string) int {
+	kept := make([]Pairing, 0, len(h.Pairings))
+	for _, pairing := range h.Pairings {
+		if pairing.BatchID != batchID {
+			kept = append(kept, pairing)
+		}
+	}
+	removed := len(h.Pairings) - len(kept)
+	h.Pairings = kept
+	return removed
+}
+
+// LastPaired returns the date of the most recent pairing of two people before
+// the reference date, and false if they were never paired before it
+func (h *PairingHistory) LastPaired(email1 string, email2 string, reference time.Time) (time.Time, bool) {
+	var last time.Time
+	found := false
+	for _, pairing := range h.Pairings {
+		if !pairing.Date.Before(reference) || found && !pairing.Date.After(last) {
+			continue
+		}
+		if slices.Contains(pairing.People, email1) && slices.Contains(pairing.People, email2) {
+			last = pairing.Date
+			found = true
+		}
+	}
+	return last, found
+}
+
+// Recency measures how recently two people were paired before the reference
+// date, from 1 when paired within the last week down to 0 when not paired
+// within the given number of weeks
+func (h *PairingHistory) Recency(email1 string, email2 string, reference time.Time, weeks int) float64 {
+	if h == nil || weeks <= 0 {
+		return 0
+	}
+
+	last, found := h.LastPaired(email1, email2, reference)
+	if !found {
+		return 0
+	}
+
+	weeksAgo := int(reference.Sub(last).Hours() / (7 * 24))
+	if weeksAgo >= weeks {
+		return 0
+	}
+	return float64(weeks-weeksAgo) / float64(weeks)
+}
@@ -0,0 +1,100 @@
+package types
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPairingHistoryAdd(t *testing.T) {
+	history := &PairingHistory{}
+	date := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+
+	if !history.Add([]string{"b@example.com", "a@example.com"}, date, "batch1", "planning.yml") {
+		t.Error("Add() returned false for a new pairing")
+	}
+	if history.Add([]string{"a@example.com", "b@example.com"}, date, "batch2", "planning.yml") {
+		t.Error("Add() returned true for a pairing already recorded")
+	}
+	if len(history.Pairings) != 1 {
+		t.Fatalf("history has %d pairings, want 1", len(history.Pairings))
+	}
+	if history.Pairings[0].People[0] != "a@example.com" {
+		t.Errorf("Add() did not sort people: %v", history.Pairings[0].People)
+	}
+
+	history.Add([]string{"a@example.com", "c@example.com"}, date, "batch2", "planning.yml")
+	if removed := history.RemoveBatch("batch2"); removed != 1 {
+		t.Errorf("RemoveBatch() removed %d pairings, want 1", removed)
+	}
+	if len(history.Pairings) != 1 || history.Pairings[0].BatchID != "batch1" {
+		t.Errorf("RemoveBatch() kept %+v, want only the pairing of batch1", history.Pairings)
+	}
+}
+
+func TestPairingHistoryRecency(t *testing.T) {
+	reference := time.Date(2024, 4, 29, 0, 0, 0, 0, time.UTC)
+	history := &PairingHistory{}
+	history.Add([]string{"a@example.com", "b@example.com"}, reference.AddDate(0, 0, -25), "", "")
+	history.Add([]string{"a@example.com", "b@example.com"}, reference.AddDate(0, 0, -10), "", "")
+	history.Add([]string{"a@example.com", "c@example.com"}, reference.AddDate(0, 0, -3), "", "")
+	history.Add([]string{"a@example.com", "d@example.com"}, reference.AddDate(0, 0, 3), "", "")
+
+	last, found := history.LastPaired("b@example.com", "a@example.com", reference)
+	if !found || !last.Equal(reference.AddDate(0, 0, -10)) {
+		t.Errorf("LastPaired() = %v, %v, want the most recent pairing", last, found)
+	}
+
+	tests := []struct {
+		name  string
+		email string
+		weeks int
+		want  float64
+	}{
+		{name: "paired last week", email: "c@example.com", weeks: 4, want: 1},
+		{name: "paired two weeks ago", email: "b@example.com", weeks: 4, want: 0.75},
+		{name: "paired before the window", email: "b@example.com", weeks: 1, want: 0},
+		{name: "paired after the reference", email: "d@example.com", weeks: 4, want: 0},
+		{name: "never paired", email: "e@example.com", weeks: 4, want: 0},
+		{name: "disabled", email: "c@example.com", weeks: 0, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := history.Recency("a@example.com", tt.email, reference, tt.weeks); got != tt.want {
+				t.Errorf("Recency() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	var nilHistory *PairingHistory
+	if got := nilHistory.Recency("a@example.com", "b@example.com", reference, 4); got != 0 {
+		t.Errorf("Recency() on a nil history = %v, want 0", got)
+	}
+}
+
+func TestPairingHistorySaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history", "pairings.json")
+
+	history, err := LoadPairingHistory(path)
+	if err != nil {
+		t.Fatalf("LoadPairingHistory() on a missing file returned error: %v", err)
+	}
+	if len(history.Pairings) != 0 {
+		t.Errorf("LoadPairingHistory() on a missing file returned %d pairings, want 0", len(history.Pairings))
+	}
+
+	date := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	history.Add([]string{"a@example.com", "b@example.com"}, date, "batch1", "planning.yml")
+	if err := history.Save(path); err != nil {
+		t.Fatalf("Save() returned error: %v", err)
+	}
+
+	loaded, err := LoadPairingHistory(path)
+	if err != nil {
+		t.Fatalf("LoadPairingHistory() returned error: %v", err)
+	}
+	if len(loaded.Pairings) != 1 || !loaded.Pairings[0].Date.Equal(date) || loaded.Pairings[0].BatchID != "batch1" {
+		t.Errorf("LoadPairingHistory() = %+v, want the saved pairing", loaded.Pairings)
+	}
+}
@@ -3,17 +3,63 @@ package types
 import (
 	"fmt"
 	"os"
+	"slices"
 
 	"gopkg.in/yaml.v3"
 )
 
 // Person represents a person who can participate in review sessions
 type Person struct {
-	Email              string   `yaml:"email"`
-	IsGoodReviewer     bool     `yaml:"isgoodreviewer"`
-	MaxSessionsPerWeek int      `yaml:"maxsessionsperweek"`
-	Skills             []string `yaml:"skills"`
-	sessionCount       int
+	Email              string `yaml:"email"`
+	MaxSessionsPerWeek int    `yaml:"maxsessionsperweek"`
+
+	// Skills maps the areas of expertise of the person to their proficiency
+	Skills Skills `yaml:"skills"`
+
+	// Team and Department are the team of the person and the department of
+	// the team, used to pair people across teams
+	Team       string `yaml:"team,omitempty"`
+	Department string `yaml:"department,omitempty"`
+
+	// Level is the seniority level of the person, e.g. senior
+	Level string `yaml:"level,omitempty"`
+
+	// IsGoodReviewer is kept for group files written before seniority levels:
+	// good reviewers without a level are at the good reviewer level
+	IsGoodReviewer bool `yaml:"isgoodreviewer"`
+
+	// MaxSessionsPerDay caps the sessions of the person on a single day,
+	// overriding the global setting when positive
+	MaxSessionsPerDay int `yaml:"maxsessionsperday,omitempty"`
+
+	// Avoid lists the emails of people this person must never be paired with
+	Avoid []string `yaml:"avoid,omitempty"`
+
+	// PairWith lists the emails of people this person must be paired with:
+	// every squad of the person includes one of them
+	PairWith []string `yaml:"pairwith,omitempty"`
+
+	// BlockedDays lists the weekdays without sessions for this person, e.g. Wednesday
+	BlockedDays []string `yaml:"blockeddays,omitempty"`
+
+	// PreferredDays lists the weekdays this person prefers for sessions
+	PreferredDays []string `yaml:"preferreddays,omitempty"`
+
+	// PreferredHours lists the hour ranges this person prefers for sessions,
+	// formatted as HH:MM-HH:MM
+	PreferredHours []string `yaml:"preferredhours,omitempty"`
+
+	// Timezone is the IANA time zone of the person, e.g. America/Montreal.
+	// Days and hours of the person are read in this zone.
+	Timezone string `yaml:"timezone,omitempty"`
+
+	// WorkingHours lists the hour ranges the person works, in their time zone,
+	// formatted as HH:MM-HH:MM. Sessions of the person stay within them.
+	WorkingHours []string `yaml:"workinghours,omitempty"`
+
+	// WorkingDays lists the weekdays the person works, e.g. for part-time
+	// schedules. The person works every day of the week if empty.
+	WorkingDays []string `yaml:"workingdays,omitempty"`
 }
 
 // Validate checks if the person's data is valid
@@ -24,7 +70,80 @@ func (p *Person) Validate() error {
 	if p.MaxSessionsPerWeek < 0 {
 		return fmt.Errorf("maxSessionsPerWeek must be non-negative")
 	}
-	return nil
+	if p.MaxSessionsPerDay < 0 {
+		return fmt.Errorf("maxSessionsPerDay must be non-negative")
+	}
+	if err := p.Skills.validate(); err != nil {
+		return err
+	}
+	if slices.Contains(p.Avoid, p.Email) || slices.Contains(p.PairWith, p.Email) {
+		return fmt.Errorf("avoid and pairwith must not contain the person's own email")
+	}
+	for _, email := range p.PairWith {
+		if slices.Contains(p.Avoid, email) {
+			return fmt.Errorf("%s is both in avoid and pairwith", email)
+		}
+	}
+	if err := p.validatePreferences(); err != nil {
+		return err
+	}
+	return p.validateWorkingHours()
+}
+
+// Avoids returns true if either person must never be paired with the other
+func (p *Person) Avoids(other *Person) bool {
+	return slices.Contains(p.Avoid, other.Email) || slices.Contains(other.Avoid, p.Email)
+}
+
+// PairingAllowed returns true if the people can share a session: no one avoids
+// another one, and everyone with required partners in the group has one of
+// them among the people. Required partners missing from the group, e.g.
+// unavailable this week, are ignored.
+func PairingAllowed(people []*Person, group []*Person) bool {
+	for i, person := range people {
+		for j := i + 1; j < len(people); j++ {
+			if person.Avoids(people[j]) {
+				return false
+			}
+		}
+		if hasPartner(person, group) && !hasPartner(person, people) {
+			return false
+		}
+	}
+	return true
+}
+
+// PairingRequired returns true if someone among the people is required to be
+// paired with another one of them
+func PairingRequired(people []*Person) bool {
+	for _, person := range people {
+		if hasPartner(person, people) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasPartner returns true if one of the people is a required partner of the person
+func hasPartner(person *Person, people []*Person) bool {
+	if len(person.PairWith) == 0 {
+		return false
+	}
+	for _, other := range people {
+		if other != person && slices.Contains(person.PairWith, other.Email) {
+			return true
+		}
+	}
+	return false
+}
+
+// DailyLimit returns the maximum number of sessions of the person per day,
+// the given default one unless the person has their own, 0 for no limit
+func (p *Person) DailyLimit(defaultLimit int) int {
+	if p.MaxSessionsPerDay > 0 {
+		return p.MaxSessionsPerDay
+	}
+	return defaultLimit
 }
 
 // CanParticipateInSession checks if the person can participate in a session
@@ -46,23 +165,22 @@ func LoadPersons(path string) ([]*Person, error) {
 	}
 
 	// Validate all persons
+	emails := make([]string, 0, len(persons))
 	for _, person := range persons {
 		if err := person.Validate(); err != nil {
 			return nil, fmt.Errorf("invalid person %s: %w", person.Email, err)
 		}
+		emails = append(emails, person.Email)
 	}
 
-	return persons, nil
-}
-
-func (p *Person) IncrementSessionCount() {
-	p.sessionCount++
-}
-
-func (p *Person) GetSessionCount() int {
-	return p.sessionCount
-}
+	// Avoided and required partners must be part of the group
+	for _, person := range persons {
+		for _, email := range append(append([]string{}, person.Avoid...), person.PairWith...) {
+			if !slices.Contains(emails, email) {
+				return nil, fmt.Errorf("invalid person %s: unknown person %s in avoid or pairwith", person.Email, email)
+			}
+		}
+	}
 
-func (p *Person) ResetSessionCount() {
-	p.sessionCount = 0
+	return persons, nil
 }
@@ -2,6 +2,7 @@ package types
 
 import (
 	"os"
+	"reflect"
 	"testing"
 )
 
@@ -35,6 +36,43 @@ func TestPersonValidation(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "negative max sessions per day",
+			person: &Person{
+				Email:              "test@example.com",
+				MaxSessionsPerWeek: 2,
+				MaxSessionsPerDay:  -1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative skill proficiency",
+			person: &Person{
+				Email:              "test@example.com",
+				MaxSessionsPerWeek: 2,
+				Skills:             Skills{"go": -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "avoids self",
+			person: &Person{
+				Email:              "test@example.com",
+				MaxSessionsPerWeek: 2,
+				Avoid:              []string{"test@example.com"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "avoids and pairs with the same person",
+			person: &Person{
+				Email:              "test@example.com",
+				MaxSessionsPerWeek: 2,
+				Avoid:              []string{"other@example.com"},
+				PairWith:           []string{"other@example.com"},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -78,27 +116,65 @@ func TestPersonSessionParticipation(t *testing.T) {
 	}
 }
 
-func TestPersonSessionCount(t *testing.T) {
-	p := &Person{
-		Email:              "test@example.com",
-		MaxSessionsPerWeek: 2,
+func TestPairingAllowed(t *testing.T) {
+	manager := &Person{Email: "manager@example.com"}
+	report := &Person{Email: "report@example.com", Avoid: []string{"manager@example.com"}}
+	newcomer := &Person{Email: "newcomer@example.com", PairWith: []string{"buddy@example.com"}}
+	buddy := &Person{Email: "buddy@example.com"}
+	group := []*Person{manager, report, newcomer, buddy}
+
+	tests := []struct {
+		name         string
+		people       []*Person
+		group        []*Person
+		wantAllowed  bool
+		wantRequired bool
+	}{
+		{name: "avoided by the second person", people: []*Person{manager, report}, group: group, wantAllowed: false},
+		{name: "avoided in a trio", people: []*Person{buddy, report, manager}, group: group, wantAllowed: false},
+		{name: "required pair", people: []*Person{newcomer, buddy}, group: group, wantAllowed: true, wantRequired: true},
+		{name: "missing required partner", people: []*Person{newcomer, manager}, group: group, wantAllowed: false},
+		{name: "required partner unavailable", people: []*Person{newcomer, manager}, group: []*Person{manager, report, newcomer}, wantAllowed: true},
+		{name: "buddy with someone else", people: []*Person{buddy, manager}, group: group, wantAllowed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PairingAllowed(tt.people, tt.group); got != tt.wantAllowed {
+				t.Errorf("PairingAllowed() = %v, want %v", got, tt.wantAllowed)
+			}
+			if got := PairingRequired(tt.people); got != tt.wantRequired {
+				t.Errorf("PairingRequired() = %v, want %v", got, tt.wantRequired)
+			}
+		})
 	}
+}
+
+func TestLoadPersonsUnknownPartner(t *testing.T) {
+	content := `
+- email: person1@example.com
+  maxsessionsperweek: 2
+  avoid:
+    - person3@example.com
+- email: person2@example.com
+  maxsessionsperweek: 1
+`
 
-	// Test initial count
-	if got := p.GetSessionCount(); got != 0 {
-		t.Errorf("GetSessionCount() = %v, want 0", got)
+	tmpFile, err := os.CreateTemp("", "persons-*.yaml")
+	if err != nil {
+		t.Fatalf("Failed to create temporary file: %v", err)
 	}
+	defer os.Remove(tmpFile.Name())
 
-	// Test increment
-	p.IncrementSessionCount()
-	if got := p.GetSessionCount(); got != 1 {
-		t.Errorf("GetSessionCount() after increment = %v, want 1", got)
+	if _, err := tmpFile.Write([]byte(content)); err != nil {
+		t.Fatalf("Failed to write to temporary file: %v", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		t.Fatalf("Failed to close temporary file: %v", err)
 	}
 
-	// Test reset
-	p.ResetSessionCount()
-	if got := p.GetSessionCount(); got != 0 {
-		t.Errorf("GetSessionCount() after reset = %v, want 0", got)
+	if _, err := LoadPersons(tmpFile.Name()); err == nil {
+		t.Error("LoadPersons() returned no error for an unknown avoided person")
 	}
 }
 
@@ -109,13 +185,16 @@ func TestLoadPersons(t *testing.T) {
   isgoodreviewer: true
   maxsessionsperweek: 2
   skills:
-    - go
+    - go: 3
     - python
 - email: person2@example.com
   isgoodreviewer: false
+  level: mid
   maxsessionsperweek: 1
   skills:
-    - java
+    java: 0
+  avoid:
+    - person1@example.com
 `
 
 	tmpFile, err := os.CreateTemp("", "persons-*.yaml")
@@ -152,8 +231,8 @@ func TestLoadPersons(t *testing.T) {
 	if person1.MaxSessionsPerWeek != 2 {
 		t.Errorf("Person1 MaxSessionsPerWeek = %v, want 2", person1.MaxSessionsPerWeek)
 	}
-	if len(person1.Skills) != 2 || person1.Skills[0] != "go" || person1.Skills[1] != "python" {
-		t.Errorf("Person1 Skills = %v, want [go python]", person1.Skills)
+	if !reflect.DeepEqual(person1.Skills, Skills{"go": 3, "python": DefaultProficiency}) {
+		t.Errorf("Person1 Skills = %v, want map[go:3 python:1]", person1.Skills)
 	}
 
 	// Verify second person
@@ -164,10 +243,16 @@ func TestLoadPersons(t *testing.T) {
 	if person2.IsGoodReviewer {
 		t.Error("Person2 IsGoodReviewer = true, want false")
 	}
+	if person2.Level != "mid" {
+		t.Errorf("Person2 Level = %v, want mid", person2.Level)
+	}
 	if person2.MaxSessionsPerWeek != 1 {
 		t.Errorf("Person2 MaxSessionsPerWeek = %v, want 1", person2.MaxSessionsPerWeek)
 	}
-	if len(person2.Skills) != 1 || person2.Skills[0] != "java" {
-		t.Errorf("Person2 Skills = %v, want [java]", person2.Skills)
+	if !reflect.DeepEqual(person2.Skills, Skills{"java": 0}) {
+		t.Errorf("Person2 Skills = %v, want map[java:0]", person2.Skills)
+	}
+	if len(person2.Avoid) != 1 || person2.Avoid[0] != "person1@example.com" {
+		t.Errorf("Person2 Avoid = %v, want [person1@example.com]", person2.Avoid)
 	}
 }
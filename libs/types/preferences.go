@@ -0,0 +1,86 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// parseWeekday parses an English weekday name, case-insensitively
+func parseWeekday(name string) (time.Weekday, error) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(day.String(), strings.TrimSpace(name)) {
+			return day, nil
+		}
+	}
+	return time.Sunday, fmt.Errorf("invalid weekday %q", name)
+}
+
+// parseHourRange parses a range of hours such as "09:30-12:00" into minutes
+// since midnight
+func parseHourRange(value string) (int, int, error) {
+	var startHour, startMinute, endHour, endMinute int
+	if _, err := fmt.Sscanf(strings.ReplaceAll(value, " ", ""), "%d:%d-%d:%d", &startHour, &startMinute, &endHour, &endMinute); err != nil {
+		return 0, 0, fmt.Errorf("invalid hour range %q: must be formatted as HH:MM-HH:MM", value)
+	}
+
+	start := startHour*60 + startMinute
+	end := endHour*60 + endMinute
+	if startHour < 0 || startHour > 24 || endHour < 0 || endHour > 24 || startMinute < 0 || startMinute >= 60 || endMinute < 0 || endMinute >= 60 || end > 24*60 {
+		return 0, 0, fmt.Errorf("invalid hour range %q: hours must be between 00:00 and 24:00", value)
+	}
+	if end <= start {
+		return 0, 0, fmt.Errorf("invalid hour range %q: end must be after start", value)
+	}
+	return start, end, nil
+}
+
+// validatePreferences checks the days and hours preferred or blocked by the person
+func (p *Person) validatePreferences() error {
+	for _, days := range [][]string{p.BlockedDays, p.PreferredDays, p.WorkingDays} {
+		for _, day := range days {
+			if _, err := parseWeekday(day); err != nil {
+				return err
+			}
+		}
+	}
+	for _, hours := range p.PreferredHours {
+		if _, _, err := parseHourRange(hours); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// containsWeekday returns true if the day is in the list of weekday names
+func containsWeekday(days []string, day time.Weekday) bool {
+	for _, name := range days {
+		if parsed, err := parseWeekday(name); err == nil && parsed == day {
+			return true
+		}
+	}
+	return false
+}
+
+// IsBlockedOn returns true if the person has no sessions on the day of the
+// given time, in the time zone of the person
+func (p *Person) IsBlockedOn(date time.Time) bool {
+	return containsWeekday(p.BlockedDays, p.LocalTime(date).Weekday())
+}
+
+// PrefersDay returns true if the day of the given time, in the time zone of
+// the person, is one of their preferred days, or if the person has no
+// preferred days
+func (p *Person) PrefersDay(date time.Time) bool {
+	return len(p.PreferredDays) == 0 || containsWeekday(p.PreferredDays, p.LocalTime(date).Weekday())
+}
+
+// PrefersHours returns true if the range is within one of the preferred hour
+// ranges of the person, in the time zone of the person or else of the range,
+// or if the person has no preferred hours
+func (p *Person) PrefersHours(r *Range) bool {
+	if len(p.PreferredHours) == 0 {
+		return true
+	}
+	return withinHourRanges(p.PreferredHours, p.LocalTime(r.Start), r.End.Sub(r.Start))
+}
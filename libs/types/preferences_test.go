@@ -0,0 +1,77 @@
+package types
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseHourRange(t *testing.T) {
+	tests := []struct {
+		value     string
+		wantStart int
+		wantEnd   int
+		wantErr   bool
+	}{
+		{value: "09:30-12:00", wantStart: 9*60 + 30, wantEnd: 12 * 60},
+		{value: "14:00 - 24:00", wantStart: 14 * 60, wantEnd: 24 * 60},
+		{value: "12:00-09:00", wantErr: true},
+		{value: "09:60-10:00", wantErr: true},
+		{value: "morning", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		start, end, err := parseHourRange(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseHourRange(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && (start != tt.wantStart || end != tt.wantEnd) {
+			t.Errorf("parseHourRange(%q) = %d, %d, want %d, %d", tt.value, start, end, tt.wantStart, tt.wantEnd)
+		}
+	}
+}
+
+func TestPersonTimePreferences(t *testing.T) {
+	person := &Person{
+		Email:              "test@example.com",
+		MaxSessionsPerWeek: 2,
+		BlockedDays:        []string{"wednesday"},
+		PreferredDays:      []string{"Tuesday", "Thursday"},
+		PreferredHours:     []string{"09:00-12:00"},
+	}
+	if err := person.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	tuesday := time.Date(2024, 4, 2, 10, 0, 0, 0, time.UTC)
+	wednesday := tuesday.AddDate(0, 0, 1)
+
+	if person.IsBlockedOn(tuesday) || !person.IsBlockedOn(wednesday) {
+		t.Error("IsBlockedOn() should only block Wednesdays")
+	}
+	if !person.PrefersDay(tuesday) || person.PrefersDay(wednesday) {
+		t.Error("PrefersDay() should prefer Tuesdays and not Wednesdays")
+	}
+	if !person.PrefersHours(&Range{Start: tuesday, End: tuesday.Add(time.Hour)}) {
+		t.Error("PrefersHours() rejected a session within the preferred hours")
+	}
+	if person.PrefersHours(&Range{Start: tuesday.Add(90 * time.Minute), End: tuesday.Add(150 * time.Minute)}) {
+		t.Error("PrefersHours() accepted a session ending after the preferred hours")
+	}
+
+	// Without preferences, every day and hour is fine
+	other := &Person{Email: "other@example.com"}
+	if !other.PrefersDay(wednesday) || !other.PrefersHours(&Range{Start: wednesday, End: wednesday.Add(time.Hour)}) {
+		t.Error("a person without preferences should accept any day and hour")
+	}
+
+	person.BlockedDays = []string{"someday"}
+	if err := person.Validate(); err == nil {
+		t.Error("Validate() returned no error for an invalid weekday")
+	}
+	person.BlockedDays = nil
+	person.PreferredHours = []string{"afternoon"}
+	if err := person.Validate(); err == nil {
+		t.Error("Validate() returned no error for invalid preferred hours")
+	}
+}
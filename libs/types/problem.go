@@ -7,6 +7,9 @@ import (
 // Solution represents a solution to a problem
 type Solution struct {
 	Sessions []*ReviewSession
+
+	// Seed is the random seed the solution was generated with
+	Seed int64 `yaml:"seed,omitempty"`
 }
 
 // Tuples represents a collection of pairs and unpaired people
@@ -15,10 +18,31 @@ type Tuples struct {
 	UnpairedPeople []*Person
 }
 
-// Tuple represents a pair of people
+// Tuple represents a pair of people, or a trio when a third person joins them
 type Tuple struct {
 	Person1 *Person
 	Person2 *Person
+
+	// Person3 is the third person of a trio, nil for a pair
+	Person3 *Person
+}
+
+// People returns the people of the tuple
+func (t Tuple) People() []*Person {
+	if t.Person3 != nil {
+		return []*Person{t.Person1, t.Person2, t.Person3}
+	}
+	return []*Person{t.Person1, t.Person2}
+}
+
+// Emails returns the emails of the people of the tuple
+func (t Tuple) Emails() []string {
+	people := t.People()
+	emails := make([]string, len(people))
+	for i, person := range people {
+		emails[i] = person.Email
+	}
+	return emails
 }
 
 type BusyTime struct {
@@ -7,7 +7,7 @@ import (
 
 func TestProblemSerialization(t *testing.T) {
 	// Create a test problem
-	person1 := &Person{Email: "person1@example.com"}
+	person1 := &Person{Email: "person1@example.com", Skills: Skills{"go": 3, "java": 0}}
 	person2 := &Person{Email: "person2@example.com"}
 	workRange := &Range{
 		Start: time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC),
@@ -58,6 +58,11 @@ func TestProblemSerialization(t *testing.T) {
 		t.Errorf("Loaded problem has target coverage %d, want %d", loadedProblem.TargetCoverage, problem.TargetCoverage)
 	}
 
+	// Verify skills and their proficiency
+	if loaded := loadedProblem.People[0].Skills; loaded["go"] != 3 || !loaded.Has("java") || len(loaded) != 2 {
+		t.Errorf("Loaded person skills = %v, want %v", loaded, person1.Skills)
+	}
+
 	// Verify busy time person reference
 	if loadedProblem.BusyTimes[0].Person.Email != problem.BusyTimes[0].Person.Email {
 		t.Errorf("Loaded busy time person email = %v, want %v", loadedProblem.BusyTimes[0].Person.Email, problem.BusyTimes[0].Person.Email)
@@ -100,6 +105,22 @@ func TestTuples(t *testing.T) {
 	}
 }
 
+func TestTupleTrio(t *testing.T) {
+	person1 := &Person{Email: "person1@example.com"}
+	person2 := &Person{Email: "person2@example.com"}
+	person3 := &Person{Email: "person3@example.com"}
+
+	pair := Tuple{Person1: person1, Person2: person2}
+	if got := pair.Emails(); len(got) != 2 || got[0] != person1.Email || got[1] != person2.Email {
+		t.Errorf("Emails() of a pair = %v, want %s and %s", got, person1.Email, person2.Email)
+	}
+
+	trio := Tuple{Person1: person1, Person2: person2, Person3: person3}
+	if got := trio.People(); len(got) != 3 || got[2] != person3 {
+		t.Errorf("People() of a trio = %v, want the three people", got)
+	}
+}
+
 func TestSolution(t *testing.T) {
 	person1 := &Person{Email: "person1@example.com"}
 	person2 := &Person{Email: "person2@example.com"}
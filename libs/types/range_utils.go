@@ -48,8 +48,10 @@ func (r *Range) Pad(padding time.Duration) *Range {
 	}
 }
 
-// GenerateTimeRanges generates time ranges for the given work ranges
-func GenerateTimeRanges(workRanges []*Range, sessionDuration time.Duration) []*Range {
+// GenerateTimeRanges generates time ranges for the given work ranges. Ranges
+// are shuffled with the given random generator before being sorted, so equal
+// length ranges come out in an order that only depends on its seed.
+func GenerateTimeRanges(workRanges []*Range, sessionDuration time.Duration, r *rand.Rand) []*Range {
 	ranges := []*Range{}
 
 	for _, workRange := range workRanges {
@@ -69,7 +71,7 @@ func GenerateTimeRanges(workRanges []*Range, sessionDuration time.Duration) []*R
 
 	// Shuffle the ranges
 	for i := range ranges {
-		j := rand.Intn(i + 1)
+		j := r.Intn(i + 1)
 		ranges[i], ranges[j] = ranges[j], ranges[i]
 	}
 
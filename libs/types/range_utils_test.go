@@ -1,6 +1,7 @@
 package types
 
 import (
+	"math/rand"
 	"testing"
 	"time"
 )
@@ -86,7 +87,7 @@ func TestGenerateTimeRanges(t *testing.T) {
 
 	// Generate time ranges with 30-minute sessions
 	sessionDuration := 30 * time.Minute
-	ranges := GenerateTimeRanges([]*Range{workRange}, sessionDuration)
+	ranges := GenerateTimeRanges([]*Range{workRange}, sessionDuration, rand.New(rand.NewSource(1)))
 
 	// Verify that we got the correct number of ranges
 	// 8 hours = 480 minutes, with 30-minute sessions = 16 sessions
@@ -124,3 +125,19 @@ func TestGenerateTimeRanges(t *testing.T) {
 		}
 	}
 }
+
+func TestGenerateTimeRangesSeed(t *testing.T) {
+	workRange := &Range{
+		Start: time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC),
+		End:   time.Date(2024, 4, 1, 17, 0, 0, 0, time.UTC),
+	}
+
+	first := GenerateTimeRanges([]*Range{workRange}, 30*time.Minute, rand.New(rand.NewSource(7)))
+	second := GenerateTimeRanges([]*Range{workRange}, 30*time.Minute, rand.New(rand.NewSource(7)))
+
+	for i := range first {
+		if !first[i].Start.Equal(second[i].Start) {
+			t.Fatalf("GenerateTimeRanges() with the same seed returned different orders at index %d", i)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// Rotation is a round robin schedule over a group, built with the circle
+// method: each round pairs everyone, and everyone meets everyone exactly once
+// before the rounds repeat
+type Rotation struct {
+	// People lists the emails of the group in circle order. An odd group gets
+	// an empty email, whose partner sits out the round.
+	People []string `json:"people"`
+
+	// Weeks records the round played each week, in order
+	Weeks []RotationWeek `json:"weeks"`
+}
+
+// RotationWeek records the round of the rotation played during a week
+type RotationWeek struct {
+	Week  string `json:"week"`
+	Round int    `json:"round"`
+}
+
+// rotationWeekFormat formats the first day of the weeks of a rotation
+const rotationWeekFormat = "2006-01-02"
+
+// NewRotation returns a rotation starting with the people in the given order
+func NewRotation(emails []string) *Rotation {
+	people := append([]string{}, emails...)
+	if len(people)%2 != 0 {
+		people = append(people, "")
+	}
+	return &Rotation{People: people, Weeks: []RotationWeek{}}
+}
+
+// LoadRotation reads a rotation file. A missing file is no rotation.
+func LoadRotation(path string) (*Rotation, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var rotation Rotation
+	if err := json.Unmarshal(data, &rotation); err != nil {
+		return nil, err
+	}
+	return &rotation, nil
+}
+
+// Save writes the rotation to a file, creating its directory if needed
+func (r *Rotation) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Rounds returns the number of rounds before pairs repeat
+func (r *Rotation) Rounds() int {
+	return max(len(r.People)-1, 1)
+}
+
+// Renew updates the people of the rotation with the people of the group when
+// the week starts a new cycle: people who left the group leave the circle and
+// newcomers join it at the end, in the given order. The new circle starts again
+// from its first round, forgetting the weeks played before. It returns true if
+// the circle changed. Within a cycle the circle is kept, so that everyone
+// meets everyone exactly once before any repeat.
+func (r *Rotation) Renew(emails []string, week time.Time) bool {
+	if !r.startsCycle(week) {
+		return false
+	}
+
+	people := slices.DeleteFunc(slices.Clone(r.People), func(email string) bool {
+		return email == "" || !slices.Contains(emails, email)
+	})
+	for _, email := range emails {
+		if !slices.Contains(people, email) {
+			people = append(people, email)
+		}
+	}
+
+	renewed := NewRotation(people)
+	if slices.Equal(renewed.People, r.People) {
+		return false
+	}
+	*r = *renewed
+	return true
+}
+
+// startsCycle returns true if the week isn't played yet and plays the first
+// round of a cycle
+func (r *Rotation) startsCycle(week time.Time) bool {
+	key := week.Format(rotationWeekFormat)
+	if slices.ContainsFunc(r.Weeks, func(played RotationWeek) bool { return played.Week == key }) {
+		return false
+	}
+	return len(r.Weeks) == 0 || r.Weeks[len(r.Weeks)-1].Round == r.Rounds()-1
+}
+
+// Contains returns true if the person is in the circle of the rotation
+func (r *Rotation) Contains(email string) bool {
+	return email != "" && slices.Contains(r.People, email)
+}
+
+// Pairs returns the pairs of emails of a round. The first person stays in
+// place while the others turn around the circle by one seat each round.
+func (r *Rotation) Pairs(round int) [][2]string {
+	n := len(r.People)
+	if n < 2 {
+		return [][2]string{}
+	}
+
+	round %= r.Rounds()
+	seat := func(position int) string {
+		if position == 0 {
+			return r.People[0]
+		}
+		return r.People[1+(position-1+n-1-round)%(n-1)]
+	}
+
+	pairs := [][2]string{}
+	for i := 0; i < n/2; i++ {
+		person1, person2 := seat(i), seat(n-1-i)
+		if person1 != "" && person2 != "" {
+			pairs = append(pairs, [2]string{person1, person2})
+		}
+	}
+	return pairs
+}
+
+// RoundOf returns the round played during the week starting on the given
+// day. A week played before keeps its round, a new week plays the round
+// following the last one and is recorded.
+func (r *Rotation) RoundOf(week time.Time) int {
+	key := week.Format(rotationWeekFormat)
+	for _, played := range r.Weeks {
+		if played.Week == key {
+			return played.Round
+		}
+	}
+
+	round := 0
+	if len(r.Weeks) > 0 {
+		round = (r.Weeks[len(r.Weeks)-1].Round + 1) % r.Rounds()
+	}
+	r.Weeks = append(r.Weeks, RotationWeek{Week: key, Round: round})
+	return round
+}
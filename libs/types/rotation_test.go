@@ -0,0 +1,139 @@
+package types
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRotationPairs(t *testing.T) {
+	for _, size := range []int{2, 5, 6} {
+		emails := []string{}
+		for i := 0; i < size; i++ {
+			emails = append(emails, string(rune('a'+i))+"@example.com")
+		}
+		rotation := NewRotation(emails)
+
+		// Everyone meets everyone exactly once over the rounds
+		met := map[[2]string]int{}
+		for round := 0; round < rotation.Rounds(); round++ {
+			seen := map[string]bool{}
+			for _, pair := range rotation.Pairs(round) {
+				if seen[pair[0]] || seen[pair[1]] {
+					t.Errorf("Pairs(%d) of %d people pairs someone twice: %v", round, size, rotation.Pairs(round))
+				}
+				seen[pair[0]], seen[pair[1]] = true, true
+				if pair[0] > pair[1] {
+					pair[0], pair[1] = pair[1], pair[0]
+				}
+				met[pair]++
+			}
+			if want := size / 2; len(rotation.Pairs(round)) != want {
+				t.Errorf("Pairs(%d) of %d people returned %d pairs, want %d", round, size, len(rotation.Pairs(round)), want)
+			}
+		}
+		if want := size * (size - 1) / 2; len(met) != want {
+			t.Errorf("rotation of %d people has %d distinct pairs, want %d", size, len(met), want)
+		}
+		for pair, count := range met {
+			if count != 1 {
+				t.Errorf("rotation of %d people pairs %v %d times, want 1", size, pair, count)
+			}
+		}
+
+		// Rounds repeat after a full rotation
+		if got, want := rotation.Pairs(rotation.Rounds()), rotation.Pairs(0); len(got) != len(want) || got[0] != want[0] {
+			t.Errorf("Pairs(%d) = %v, want the pairs of round 0 %v", rotation.Rounds(), got, want)
+		}
+		if !rotation.Contains(emails[0]) || rotation.Contains("") || rotation.Contains("z@example.com") {
+			t.Errorf("Contains() is wrong for a rotation of %d people", size)
+		}
+	}
+}
+
+func TestRotationRoundOf(t *testing.T) {
+	rotation := NewRotation([]string{"a@example.com", "b@example.com", "c@example.com"})
+	week := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	if round := rotation.RoundOf(week); round != 0 {
+		t.Errorf("RoundOf() first week = %d, want 0", round)
+	}
+	// Skipped weeks don't skip rounds, and a week keeps its round
+	if round := rotation.RoundOf(week.AddDate(0, 0, 14)); round != 1 {
+		t.Errorf("RoundOf() second played week = %d, want 1", round)
+	}
+	if round := rotation.RoundOf(week); round != 0 {
+		t.Errorf("RoundOf() first week again = %d, want 0", round)
+	}
+	rotation.RoundOf(week.AddDate(0, 0, 21))
+	if round := rotation.RoundOf(week.AddDate(0, 0, 28)); round != 0 {
+		t.Errorf("RoundOf() after a full rotation = %d, want 0", round)
+	}
+
+	// The rotation is saved and continued
+	path := filepath.Join(t.TempDir(), "rotation", "rotation.json")
+	if err := rotation.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, err := LoadRotation(path)
+	if err != nil {
+		t.Fatalf("LoadRotation() error = %v", err)
+	}
+	if len(loaded.People) != 4 || len(loaded.Weeks) != 4 {
+		t.Errorf("LoadRotation() = %+v, want the saved rotation", loaded)
+	}
+	if round := loaded.RoundOf(week.AddDate(0, 0, 35)); round != 1 {
+		t.Errorf("RoundOf() after loading = %d, want 1", round)
+	}
+
+	if missing, err := LoadRotation(filepath.Join(t.TempDir(), "missing.json")); missing != nil || err != nil {
+		t.Errorf("LoadRotation() of a missing file = %v, %v, want nil, nil", missing, err)
+	}
+}
+
+func TestRotationRenew(t *testing.T) {
+	a, b, c, d, e := "a@example.com", "b@example.com", "c@example.com", "d@example.com", "e@example.com"
+	week := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	rotation := NewRotation([]string{a, b, c, d})
+
+	// A new rotation starts its cycle with the group it was created for
+	if rotation.Renew([]string{a, b, c, d}, week) {
+		t.Error("Renew() changed a rotation over the same group")
+	}
+	rotation.RoundOf(week)
+
+	// Within a cycle, newcomers and leavers don't change the circle
+	for i := 1; i < rotation.Rounds(); i++ {
+		played := week.AddDate(0, 0, 7*i)
+		if rotation.Renew([]string{a, b, c, e}, played) {
+			t.Errorf("Renew() changed the circle during round %d", i)
+		}
+		if round := rotation.RoundOf(played); round != i {
+			t.Errorf("RoundOf() = %d during the cycle, want %d", round, i)
+		}
+	}
+	// Replaying a week of the cycle keeps the circle too
+	if rotation.Renew([]string{a, b, c, e}, week) {
+		t.Error("Renew() changed the circle when replaying the first week")
+	}
+
+	// The next cycle drops the leavers and adds the newcomers at the end
+	next := week.AddDate(0, 0, 7*rotation.Rounds())
+	if !rotation.Renew([]string{a, b, c, e}, next) {
+		t.Fatal("Renew() kept the circle at the start of a cycle with a changed group")
+	}
+	if want := []string{a, b, c, e}; !slices.Equal(rotation.People, want) {
+		t.Errorf("Renew() circle = %v, want %v", rotation.People, want)
+	}
+	if round := rotation.RoundOf(next); round != 0 {
+		t.Errorf("RoundOf() after Renew() = %d, want 0", round)
+	}
+
+	// An odd group gets someone sitting out each round
+	odd := week.AddDate(0, 0, 7*(rotation.Rounds()+10))
+	rotation.Weeks = []RotationWeek{{Week: week.Format(rotationWeekFormat), Round: rotation.Rounds() - 1}}
+	if !rotation.Renew([]string{a, b, c, d, e}, odd) || len(rotation.People) != 6 || rotation.People[5] != "" {
+		t.Errorf("Renew() circle of an odd group = %v, want 5 people and an empty seat", rotation.People)
+	}
+}
@@ -0,0 +1,78 @@
+package types
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Seniority ranks people by seniority level
+type Seniority struct {
+	// Levels lists the seniority levels, from the most junior one
+	Levels []string
+
+	// GoodReviewerLevel is the level of people flagged as good reviewers
+	// without a level
+	GoodReviewerLevel string
+}
+
+// DefaultSeniority has four levels, good reviewers being seniors
+var DefaultSeniority = Seniority{
+	Levels:            []string{"junior", "mid", "senior", "staff"},
+	GoodReviewerLevel: "senior",
+}
+
+// Validate checks that the levels are unique and include the good reviewer level
+func (s Seniority) Validate() error {
+	if len(s.Levels) == 0 {
+		return fmt.Errorf("at least one seniority level is required")
+	}
+	for i, level := range s.Levels {
+		if s.index(level) != i {
+			return fmt.Errorf("seniority level %q is listed twice", level)
+		}
+	}
+	if s.index(s.GoodReviewerLevel) < 0 {
+		return fmt.Errorf("good reviewer level %q is not a seniority level (%s)", s.GoodReviewerLevel, strings.Join(s.Levels, ", "))
+	}
+	return nil
+}
+
+// ValidatePeople checks that the level of every person is a seniority level
+func (s Seniority) ValidatePeople(people []*Person) error {
+	for _, person := range people {
+		if person.Level != "" && s.index(person.Level) < 0 {
+			return fmt.Errorf("level %q of %s is not a seniority level (%s)", person.Level, person.Email, strings.Join(s.Levels, ", "))
+		}
+	}
+	return nil
+}
+
+// Rank returns the index of a level, 0 being the most junior one, or -1 for
+// an unknown level
+func (s Seniority) Rank(level string) int {
+	return s.index(level)
+}
+
+// PersonRank returns the index of the level of the person. People without a
+// level are at the good reviewer level when flagged as good reviewers, at the
+// most junior level otherwise.
+func (s Seniority) PersonRank(person *Person) int {
+	level := person.Level
+	if level == "" && person.IsGoodReviewer {
+		level = s.GoodReviewerLevel
+	}
+	return max(s.index(level), 0)
+}
+
+// IsGoodReviewer returns true if the person is at the good reviewer level or above
+func (s Seniority) IsGoodReviewer(person *Person) bool {
+	return s.PersonRank(person) >= s.index(s.GoodReviewerLevel)
+}
+
+// index returns the index of a level, matched case-insensitively, or -1
+func (s Seniority) index(level string) int {
+	return slices.IndexFunc(s.Levels, func(candidate string) bool {
+		return strings.EqualFold(candidate, level)
+	})
+}
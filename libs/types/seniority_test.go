@@ -0,0 +1,44 @@
+package types
+
+import "testing"
+
+func TestSeniority(t *testing.T) {
+	staff := &Person{Email: "staff@example.com", Level: "Staff"}
+	goodReviewer := &Person{Email: "good@example.com", IsGoodReviewer: true}
+	leveledGoodReviewer := &Person{Email: "leveled@example.com", Level: "mid", IsGoodReviewer: true}
+	newcomer := &Person{Email: "newcomer@example.com"}
+
+	seniority := DefaultSeniority
+	tests := []struct {
+		person       *Person
+		rank         int
+		goodReviewer bool
+	}{
+		{staff, 3, true},
+		{goodReviewer, 2, true},
+		{leveledGoodReviewer, 1, false},
+		{newcomer, 0, false},
+	}
+	for _, tt := range tests {
+		if got := seniority.PersonRank(tt.person); got != tt.rank {
+			t.Errorf("PersonRank(%s) = %d, want %d", tt.person.Email, got, tt.rank)
+		}
+		if got := seniority.IsGoodReviewer(tt.person); got != tt.goodReviewer {
+			t.Errorf("IsGoodReviewer(%s) = %v, want %v", tt.person.Email, got, tt.goodReviewer)
+		}
+	}
+
+	if err := seniority.ValidatePeople([]*Person{staff, goodReviewer, newcomer}); err != nil {
+		t.Errorf("ValidatePeople() returned error: %v", err)
+	}
+	if err := seniority.ValidatePeople([]*Person{{Email: "lead@example.com", Level: "lead"}}); err == nil {
+		t.Error("ValidatePeople() returned no error for an unknown level")
+	}
+
+	if err := (Seniority{Levels: []string{"junior", "junior"}, GoodReviewerLevel: "junior"}).Validate(); err == nil {
+		t.Error("Validate() returned no error for a level listed twice")
+	}
+	if err := (Seniority{Levels: []string{"junior", "senior"}, GoodReviewerLevel: "staff"}).Validate(); err == nil {
+		t.Error("Validate() returned no error for an unknown good reviewer level")
+	}
+}
@@ -38,6 +38,19 @@ func (s *ReviewSession) GetEventSummary() string {
 	return fmt.Sprintf("%s - %s", sessionPrefix, s.Reviewers.GetDisplayName())
 }
 
+// GetEventDescription lists the session times of each attendee, in their
+// own time zone
+func (s *ReviewSession) GetEventDescription() string {
+	lines := make([]string, 0, len(s.Reviewers.People))
+	for _, person := range s.Reviewers.People {
+		start := person.LocalTime(s.Range.Start)
+		end := person.LocalTime(s.Range.End)
+		lines = append(lines, fmt.Sprintf("%s: %s %s - %s (%s)",
+			person.Email, start.Format("Monday 2006-01-02"), start.Format("15:04"), end.Format("15:04"), start.Location()))
+	}
+	return strings.Join(lines, "\n")
+}
+
 // Validate checks if the session is valid
 func (s *ReviewSession) Validate() error {
 	if s.Reviewers == nil {
@@ -49,26 +62,60 @@ func (s *ReviewSession) Validate() error {
 	return s.Reviewers.Validate()
 }
 
-// Squad represents a pair of reviewers
+// MinSquadSize is the smallest number of people in a squad
+const MinSquadSize = 2
+
+// Squad represents a group of reviewers attending a session together
 type Squad struct {
 	People     []*Person
 	BusyRanges []*Range
+
+	// RepeatPenalty measures how recently the people of the squad were paired,
+	// from 0 when not paired recently to 1 when paired the week before
+	RepeatPenalty float64 `yaml:"-"`
+
+	// TeamPenalty measures how much the squad stays within teams, from 0 when
+	// the team policy doesn't penalize it to 1 when all its people are of the
+	// same team
+	TeamPenalty float64 `yaml:"-"`
 }
 
 // Validate checks if the squad is valid
 func (s *Squad) Validate() error {
-	if len(s.People) != 2 {
-		return fmt.Errorf("squad must have exactly 2 people")
+	if len(s.People) < MinSquadSize {
+		return fmt.Errorf("squad must have at least %d people", MinSquadSize)
 	}
-	if s.People[0] == nil || s.People[1] == nil {
-		return fmt.Errorf("people cannot be nil")
+	seen := map[*Person]bool{}
+	for _, person := range s.People {
+		if person == nil {
+			return fmt.Errorf("people cannot be nil")
+		}
+		if seen[person] {
+			return fmt.Errorf("person %s appears twice in the squad", person.Email)
+		}
+		seen[person] = true
 	}
 	return nil
 }
 
-// GetDisplayName returns a display name for the squad
+// GetDisplayName returns a display name for the squad, e.g. "john & jane" or
+// "john, jane & jack"
 func (s *Squad) GetDisplayName() string {
-	person1 := strings.Split(s.People[0].Email, "@")[0]
-	person2 := strings.Split(s.People[1].Email, "@")[0]
-	return fmt.Sprintf("%s & %s", person1, person2)
+	names := make([]string, len(s.People))
+	for i, person := range s.People {
+		names[i] = strings.Split(person.Email, "@")[0]
+	}
+	if len(names) < 2 {
+		return strings.Join(names, "")
+	}
+	return fmt.Sprintf("%s & %s", strings.Join(names[:len(names)-1], ", "), names[len(names)-1])
+}
+
+// Emails returns the emails of the people of the squad
+func (s *Squad) Emails() []string {
+	emails := make([]string, len(s.People))
+	for i, person := range s.People {
+		emails[i] = person.Email
+	}
+	return emails
 }
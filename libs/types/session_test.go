@@ -48,6 +48,14 @@ func TestReviewSession(t *testing.T) {
 		t.Errorf("GetDisplayName() returned %q, want %q", session.GetDisplayName(), expectedDisplayName)
 	}
 
+	// Test GetEventDescription() method, with times in each attendee's zone
+	person2.Timezone = "America/Montreal"
+	expectedDescription := "john.doe@example.com: Monday 2024-04-01 09:00 - 10:00 (UTC)\n" +
+		"jane.smith@example.com: Monday 2024-04-01 05:00 - 06:00 (America/Montreal)"
+	if session.GetEventDescription() != expectedDescription {
+		t.Errorf("GetEventDescription() returned %q, want %q", session.GetEventDescription(), expectedDescription)
+	}
+
 	// Test Validate() method
 	if err := session.Validate(); err != nil {
 		t.Errorf("Validate() returned error: %v", err)
@@ -109,6 +117,30 @@ func TestSquad(t *testing.T) {
 		t.Error("Validate() with nil person should return error")
 	}
 
+	// Test Validate() with the same person twice
+	invalidSquad = &Squad{
+		People: []*Person{person1, person1},
+	}
+	if err := invalidSquad.Validate(); err == nil {
+		t.Error("Validate() with the same person twice should return error")
+	}
+
+	// Test squads of more than two people
+	person3 := &Person{Email: "jack.brown@example.com"}
+	trio := &Squad{
+		People: []*Person{person1, person2, person3},
+	}
+	if err := trio.Validate(); err != nil {
+		t.Errorf("Validate() of a trio returned error: %v", err)
+	}
+	expectedDisplayName = "john.doe, jane.smith & jack.brown"
+	if trio.GetDisplayName() != expectedDisplayName {
+		t.Errorf("GetDisplayName() of a trio returned %q, want %q", trio.GetDisplayName(), expectedDisplayName)
+	}
+	if emails := trio.Emails(); len(emails) != 3 || emails[2] != "jack.brown@example.com" {
+		t.Errorf("Emails() of a trio returned %v", emails)
+	}
+
 	// Test Validate() with too many people
 	invalidSquad = &Squad{
 		People: []*Person{person1, person2, person1},
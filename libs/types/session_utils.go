@@ -1,10 +1,15 @@
 package types
 
-// GenerateSessions generates all possible sessions for the given squads and time ranges
+// GenerateSessions generates all possible sessions for the given squads and
+// time ranges, keeping the ranges within the working hours of every person of
+// the squad
 func GenerateSessions(squads []*Squad, ranges []*Range) []*ReviewSession {
 	sessions := make([]*ReviewSession, 0)
 	for _, squad := range squads {
 		for _, timeRange := range ranges {
+			if !squad.IsWorkingDuring(timeRange) {
+				continue
+			}
 			sessions = append(sessions, &ReviewSession{
 				Reviewers: squad,
 				Range:     timeRange,
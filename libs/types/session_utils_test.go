@@ -62,6 +62,24 @@ func TestGenerateSessions(t *testing.T) {
 	}
 }
 
+func TestGenerateSessionsWorkingHours(t *testing.T) {
+	early := &Person{Email: "early@example.com", WorkingHours: []string{"08:00-10:00"}}
+	other := &Person{Email: "other@example.com"}
+	squads := []*Squad{{People: []*Person{early, other}}}
+
+	start := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	ranges := []*Range{
+		{Start: start, End: start.Add(time.Hour)},
+		{Start: start.Add(time.Hour), End: start.Add(2 * time.Hour)},
+	}
+
+	// Only the range within the working hours of both people is kept
+	sessions := GenerateSessions(squads, ranges)
+	if len(sessions) != 1 || sessions[0].Range != ranges[0] {
+		t.Errorf("GenerateSessions() returned %d sessions, want only the 09:00 session", len(sessions))
+	}
+}
+
 func TestByStart(t *testing.T) {
 	// Create test sessions
 	start := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
@@ -0,0 +1,80 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultProficiency is the proficiency of skills listed without one
+const DefaultProficiency = 1
+
+// Skills maps the areas of expertise of a person to their proficiency in
+// each, from 0 for someone only learning the skill
+type Skills map[string]int
+
+// UnmarshalYAML reads skills either as a mapping of skills to proficiencies,
+// or as a list of skills, optionally followed by their proficiency, e.g.
+// "- go" or "- go: 3"
+func (s *Skills) UnmarshalYAML(node *yaml.Node) error {
+	skills := Skills{}
+	switch node.Kind {
+	case yaml.MappingNode:
+		proficiencies := map[string]int{}
+		if err := node.Decode(&proficiencies); err != nil {
+			return fmt.Errorf("invalid skills: %w", err)
+		}
+		for name, proficiency := range proficiencies {
+			skills[name] = proficiency
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			if item.Kind == yaml.ScalarNode {
+				skills[item.Value] = DefaultProficiency
+				continue
+			}
+			proficiencies := map[string]int{}
+			if err := item.Decode(&proficiencies); err != nil {
+				return fmt.Errorf("invalid skill at line %d: must be a name or name: proficiency", item.Line)
+			}
+			for name, proficiency := range proficiencies {
+				skills[name] = proficiency
+			}
+		}
+	case yaml.ScalarNode:
+		if node.Tag != "!!null" {
+			return fmt.Errorf("invalid skills at line %d: must be a list or a mapping", node.Line)
+		}
+	default:
+		return fmt.Errorf("invalid skills at line %d: must be a list or a mapping", node.Line)
+	}
+	*s = skills
+	return nil
+}
+
+// validate checks that proficiencies are not negative
+func (s Skills) validate() error {
+	for name, proficiency := range s {
+		if proficiency < 0 {
+			return fmt.Errorf("proficiency of skill %s must be non-negative", name)
+		}
+	}
+	return nil
+}
+
+// Names returns the sorted names of the skills
+func (s Skills) Names() []string {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Has returns true if the skill is listed, even with a proficiency of 0
+func (s Skills) Has(name string) bool {
+	_, ok := s[name]
+	return ok
+}
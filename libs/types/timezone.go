@@ -0,0 +1,133 @@
+package types
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// locations caches the time zones loaded by name
+var locations sync.Map
+
+// loadLocation returns the time zone of the given IANA name
+func loadLocation(name string) (*time.Location, error) {
+	if location, ok := locations.Load(name); ok {
+		return location.(*time.Location), nil
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", name, err)
+	}
+	locations.Store(name, location)
+	return location, nil
+}
+
+// validateWorkingHours checks the time zone and working hours of the person
+func (p *Person) validateWorkingHours() error {
+	if p.Timezone != "" {
+		if _, err := loadLocation(p.Timezone); err != nil {
+			return err
+		}
+	}
+	for _, hours := range p.WorkingHours {
+		if _, _, err := parseHourRange(hours); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Location returns the time zone of the person, or nil if the person has none
+func (p *Person) Location() *time.Location {
+	if p.Timezone == "" {
+		return nil
+	}
+	location, err := loadLocation(p.Timezone)
+	if err != nil {
+		return nil
+	}
+	return location
+}
+
+// LocalTime returns the time in the time zone of the person, or unchanged if
+// the person has no time zone
+func (p *Person) LocalTime(t time.Time) time.Time {
+	if location := p.Location(); location != nil {
+		return t.In(location)
+	}
+	return t
+}
+
+// WorksOn returns true if the day of the given time, in the time zone of the
+// person, is one of their working days, or if the person has no working days
+func (p *Person) WorksOn(date time.Time) bool {
+	return len(p.WorkingDays) == 0 || containsWeekday(p.WorkingDays, p.LocalTime(date).Weekday())
+}
+
+// IsWorkingDuring returns true if the range is on a working day of the person
+// and within one of their working hour ranges, in their time zone. People
+// without working hours work during every work range of the problem.
+func (p *Person) IsWorkingDuring(r *Range) bool {
+	if !p.WorksOn(r.Start) {
+		return false
+	}
+	if len(p.WorkingHours) == 0 {
+		return true
+	}
+	return withinHourRanges(p.WorkingHours, p.LocalTime(r.Start), r.End.Sub(r.Start))
+}
+
+// WorkRanges returns the working hours of the person on the given day, in
+// their time zone, none if the person has no working hours or doesn't work
+// that day
+func (p *Person) WorkRanges(day time.Time) []*Range {
+	location := p.Location()
+	if location == nil {
+		location = day.Location()
+	}
+
+	ranges := []*Range{}
+	year, month, date := day.Date()
+	if !p.WorksOn(time.Date(year, month, date, 0, 0, 0, 0, location)) {
+		return ranges
+	}
+
+	// Each boundary is a wall clock time of the day, which is not a fixed
+	// offset from midnight on days the clocks change
+	at := func(minutes int) time.Time {
+		return time.Date(year, month, date, minutes/60, minutes%60, 0, 0, location)
+	}
+	for _, hours := range p.WorkingHours {
+		start, end, err := parseHourRange(hours)
+		if err != nil {
+			continue
+		}
+		ranges = append(ranges, &Range{Start: at(start), End: at(end)})
+	}
+	return ranges
+}
+
+// withinHourRanges returns true if the time and duration fit in one of the
+// hour ranges, formatted as HH:MM-HH:MM, in the time zone of the time
+func withinHourRanges(hourRanges []string, start time.Time, duration time.Duration) bool {
+	from := start.Hour()*60 + start.Minute()
+	to := from + int(duration.Minutes())
+	for _, hours := range hourRanges {
+		rangeStart, rangeEnd, err := parseHourRange(hours)
+		if err == nil && from >= rangeStart && to <= rangeEnd {
+			return true
+		}
+	}
+	return false
+}
+
+// IsWorkingDuring returns true if the range is within the working hours of
+// every person of the squad
+func (s *Squad) IsWorkingDuring(r *Range) bool {
+	for _, person := range s.People {
+		if !person.IsWorkingDuring(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,128 @@
+package types
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPersonWorkingHours(t *testing.T) {
+	montreal := &Person{
+		Email:              "montreal@example.com",
+		MaxSessionsPerWeek: 2,
+		Timezone:           "America/Montreal",
+		WorkingHours:       []string{"09:00-12:00", "13:00-17:00"},
+	}
+	if err := montreal.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Fatalf("cannot load Paris time zone: %v", err)
+	}
+
+	// 10:00 in Paris is 04:00 in Montreal, 16:00 in Paris is 10:00 there
+	morning := time.Date(2024, 4, 2, 10, 0, 0, 0, paris)
+	afternoon := time.Date(2024, 4, 2, 16, 0, 0, 0, paris)
+	if montreal.IsWorkingDuring(&Range{Start: morning, End: morning.Add(time.Hour)}) {
+		t.Error("IsWorkingDuring() accepted 10:00 Paris time for a person in Montreal")
+	}
+	if !montreal.IsWorkingDuring(&Range{Start: afternoon, End: afternoon.Add(time.Hour)}) {
+		t.Error("IsWorkingDuring() rejected 16:00 Paris time for a person in Montreal")
+	}
+	if got := montreal.LocalTime(afternoon).Hour(); got != 10 {
+		t.Errorf("LocalTime() hour = %d, want 10", got)
+	}
+
+	ranges := montreal.WorkRanges(time.Date(2024, 4, 2, 0, 0, 0, 0, paris))
+	if len(ranges) != 2 {
+		t.Fatalf("WorkRanges() returned %d ranges, want 2", len(ranges))
+	}
+	if want := time.Date(2024, 4, 2, 15, 0, 0, 0, paris); !ranges[0].Start.Equal(want) {
+		t.Errorf("WorkRanges() first range starts at %v, want %v", ranges[0].Start, want)
+	}
+
+	// The squad works during the intersection of the working hours
+	local := &Person{Email: "paris@example.com", Timezone: "Europe/Paris", WorkingHours: []string{"09:00-18:00"}}
+	squad := &Squad{People: []*Person{montreal, local}}
+	late := afternoon.Add(2 * time.Hour)
+	if !squad.IsWorkingDuring(&Range{Start: afternoon, End: afternoon.Add(time.Hour)}) {
+		t.Error("Squad.IsWorkingDuring() rejected a range within both working hours")
+	}
+	if squad.IsWorkingDuring(&Range{Start: late, End: late.Add(time.Hour)}) {
+		t.Error("Squad.IsWorkingDuring() accepted a range after the working hours of one person")
+	}
+
+	// Days are read in the time zone of the person: 01:00 on Wednesday in
+	// Paris is still Tuesday in Montreal
+	montreal.BlockedDays = []string{"tuesday"}
+	if night := time.Date(2024, 4, 3, 1, 0, 0, 0, paris); !montreal.IsBlockedOn(night) {
+		t.Error("IsBlockedOn() should read the day in the time zone of the person")
+	}
+
+	// People without working hours work during every range
+	if other := (&Person{Email: "other@example.com"}); !other.IsWorkingDuring(&Range{Start: late, End: late.Add(time.Hour)}) {
+		t.Error("IsWorkingDuring() rejected a range for a person without working hours")
+	}
+
+	// Part-time people only work on their working days
+	montreal.BlockedDays = nil
+	montreal.WorkingDays = []string{"Monday", "Wednesday"}
+	if montreal.WorksOn(afternoon) || montreal.IsWorkingDuring(&Range{Start: afternoon, End: afternoon.Add(time.Hour)}) {
+		t.Error("a part-time person should not work on Tuesday")
+	}
+	if len(montreal.WorkRanges(afternoon)) != 0 || len(montreal.WorkRanges(afternoon.AddDate(0, 0, 1))) != 2 {
+		t.Error("WorkRanges() should only return ranges on working days")
+	}
+	montreal.WorkingDays = []string{"Funday"}
+	if err := montreal.Validate(); err == nil {
+		t.Error("Validate() returned no error for an invalid working day")
+	}
+	montreal.WorkingDays = nil
+
+	montreal.Timezone = "Mars/Olympus"
+	if err := montreal.Validate(); err == nil {
+		t.Error("Validate() returned no error for an invalid timezone")
+	}
+	montreal.Timezone = ""
+	montreal.WorkingHours = []string{"17:00-09:00"}
+	if err := montreal.Validate(); err == nil {
+		t.Error("Validate() returned no error for invalid working hours")
+	}
+}
+
+func TestPersonWorkRangesDaylightSaving(t *testing.T) {
+	montreal, err := time.LoadLocation("America/Montreal")
+	if err != nil {
+		t.Fatalf("cannot load Montreal time zone: %v", err)
+	}
+	person := &Person{
+		Email:        "montreal@example.com",
+		Timezone:     "America/Montreal",
+		WorkingHours: []string{"09:00-12:00", "13:00-24:00"},
+	}
+
+	// Clocks move forward on 2024-03-10 and back on 2024-11-03 in Montreal:
+	// working hours stay at the same wall clock times
+	for _, day := range []time.Time{
+		time.Date(2024, 3, 10, 0, 0, 0, 0, montreal),
+		time.Date(2024, 11, 3, 0, 0, 0, 0, montreal),
+	} {
+		ranges := person.WorkRanges(day)
+		if len(ranges) != 2 {
+			t.Fatalf("WorkRanges(%v) returned %d ranges, want 2", day, len(ranges))
+		}
+		want := []*Range{
+			{Start: time.Date(day.Year(), day.Month(), day.Day(), 9, 0, 0, 0, montreal), End: time.Date(day.Year(), day.Month(), day.Day(), 12, 0, 0, 0, montreal)},
+			{Start: time.Date(day.Year(), day.Month(), day.Day(), 13, 0, 0, 0, montreal), End: day.AddDate(0, 0, 1)},
+		}
+		for i, r := range ranges {
+			if !r.Start.Equal(want[i].Start) || !r.End.Equal(want[i].End) {
+				t.Errorf("WorkRanges(%v)[%d] = %v - %v, want %v - %v", day.Format("2006-01-02"), i, r.Start, r.End, want[i].Start, want[i].End)
+			}
+		}
+		if local := person.LocalTime(ranges[0].Start); local.Hour() != 9 {
+			t.Errorf("WorkRanges(%v) starts at %02d:00 local time, want 09:00", day.Format("2006-01-02"), local.Hour())
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package util
 
 import (
+	"fmt"
 	"matchmaker/libs/types"
 	"os"
 	"time"
@@ -67,10 +68,18 @@ func LogRange(message string, r *types.Range) {
 
 // LogSession logs a session with standardized formatting
 func LogSession(message string, session *types.ReviewSession) {
-	LogInfo(message, map[string]interface{}{
-		"person1": session.Reviewers.People[0].Email,
-		"person2": session.Reviewers.People[1].Email,
-		"from":    session.Range.Start.Format(time.RFC3339),
-		"to":      session.Range.End.Format(time.RFC3339),
-	})
+	fields := SquadFields(session.Reviewers)
+	fields["from"] = session.Range.Start.Format(time.RFC3339)
+	fields["to"] = session.Range.End.Format(time.RFC3339)
+	LogInfo(message, fields)
+}
+
+// SquadFields returns log fields with the email of each person of the squad,
+// keyed person1, person2, etc.
+func SquadFields(squad *types.Squad) map[string]interface{} {
+	fields := map[string]interface{}{}
+	for i, person := range squad.People {
+		fields[fmt.Sprintf("person%d", i+1)] = person.Email
+	}
+	return fields
 }
@@ -147,3 +147,18 @@ func TestLogSession(t *testing.T) {
 	assert.Contains(t, buf.String(), "from=")
 	assert.Contains(t, buf.String(), "to=")
 }
+
+func TestSquadFields(t *testing.T) {
+	squad := &types.Squad{
+		People: []*types.Person{
+			{Email: "person1@example.com"},
+			{Email: "person2@example.com"},
+			{Email: "person3@example.com"},
+		},
+	}
+
+	fields := SquadFields(squad)
+	assert.Len(t, fields, 3)
+	assert.Equal(t, "person1@example.com", fields["person1"])
+	assert.Equal(t, "person3@example.com", fields["person3"])
+}
@@ -181,6 +181,86 @@ func GetWeekWorkRanges(beginOfWeek time.Time) (chan *types.Range, error) {
 	return ranges, nil
 }
 
+// GetDefaultWorkingHours returns the configured morning and afternoon hours,
+// formatted as HH:MM-HH:MM
+func GetDefaultWorkingHours() ([]string, error) {
+	hours := make([]string, 0, 2)
+	for _, period := range []string{"morning", "afternoon"} {
+		periodHours, err := config.GetWorkHoursConfig(period)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s hours configuration: %w", period, err)
+		}
+		hours = append(hours, fmt.Sprintf("%02d:%02d-%02d:%02d",
+			periodHours.StartHour, periodHours.StartMinute,
+			periodHours.EndHour, periodHours.EndMinute))
+	}
+	return hours, nil
+}
+
+// ResolveWorkingHours gives people without a time zone the configured one,
+// and people without working hours the configured morning and afternoon
+// hours, so that everyone's availability is computed in their own zone
+func ResolveWorkingHours(people []*types.Person) error {
+	defaultHours, err := GetDefaultWorkingHours()
+	if err != nil {
+		return err
+	}
+	for _, person := range people {
+		if person.Timezone == "" {
+			person.Timezone = config.GetTimezone()
+		}
+		if len(person.WorkingHours) == 0 {
+			person.WorkingHours = append([]string{}, defaultHours...)
+		}
+	}
+	return nil
+}
+
+// GetPeopleWorkRanges returns the work ranges of the week covering the working
+// hours of every person, in their own time zone, on the days of the configured
+// work ranges the person works. People without working hours follow the
+// configured work ranges. Ranges are expressed in the time zone of beginOfWeek.
+func GetPeopleWorkRanges(beginOfWeek time.Time, people []*types.Person) ([]*types.Range, error) {
+	workRangesChan, err := GetWeekWorkRanges(beginOfWeek)
+	if err != nil {
+		return nil, err
+	}
+	workRanges := ToSlice(workRangesChan)
+
+	// Working days are the days of the configured ranges, holidays excluded
+	days := []time.Time{}
+	for _, workRange := range workRanges {
+		day := time.Date(workRange.Start.Year(), workRange.Start.Month(), workRange.Start.Day(), 0, 0, 0, 0, beginOfWeek.Location())
+		if len(days) == 0 || !days[len(days)-1].Equal(day) {
+			days = append(days, day)
+		}
+	}
+
+	ranges := []*types.Range{}
+	for _, person := range people {
+		if len(person.WorkingHours) == 0 {
+			for _, workRange := range workRanges {
+				if person.WorksOn(workRange.Start) {
+					ranges = append(ranges, &types.Range{Start: workRange.Start, End: workRange.End})
+				}
+			}
+			continue
+		}
+		for _, day := range days {
+			for _, personRange := range person.WorkRanges(day) {
+				ranges = append(ranges, &types.Range{
+					Start: personRange.Start.In(beginOfWeek.Location()),
+					End:   personRange.End.In(beginOfWeek.Location()),
+				})
+			}
+		}
+	}
+	if len(people) == 0 {
+		return workRanges, nil
+	}
+	return types.MergeRanges(ranges), nil
+}
+
 // ToSlice converts a channel of ranges to a slice
 func ToSlice(c chan *types.Range) []*types.Range {
 	s := make([]*types.Range, 0)
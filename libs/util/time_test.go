@@ -1,12 +1,14 @@
 package util
 
 import (
+	"matchmaker/libs/config"
 	"matchmaker/libs/holidays"
 	"matchmaker/libs/testutils"
 	"matchmaker/libs/types"
 	"testing"
 	"time"
 
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -273,3 +275,59 @@ func TestGetWeekWorkRanges(t *testing.T) {
 			"Ranges should not include Custom Holiday")
 	}
 }
+
+func TestGetPeopleWorkRanges(t *testing.T) {
+	mockHolidays := testutils.NewMockHolidays()
+	mockHolidays.ClearHolidays()
+	originalHolidayGetter := holidays.DefaultHolidayGetter
+	holidays.DefaultHolidayGetter = func(country holidays.Country, start, end time.Time) ([]holidays.Holiday, error) {
+		return mockHolidays.GetHolidaysForRange(country, start, end)
+	}
+	defer func() {
+		holidays.DefaultHolidayGetter = originalHolidayGetter
+	}()
+
+	configMock := testutils.NewConfigMock()
+	configMock.SetupWorkHours()
+	configMock.SetMorningHours(9, 0, 12, 0)
+	configMock.SetAfternoonHours(13, 0, 17, 0)
+	defer configMock.Restore()
+	timezone := config.GetTimezone()
+	viper.Set(config.WorkingHoursTimezone, "Europe/Paris")
+	defer viper.Set(config.WorkingHoursTimezone, timezone)
+
+	paris, err := time.LoadLocation("Europe/Paris")
+	assert.NoError(t, err)
+	beginOfWeek := time.Date(2024, 3, 25, 0, 0, 0, 0, paris)
+
+	local := &types.Person{Email: "paris@example.com"}
+	montreal := &types.Person{Email: "montreal@example.com", Timezone: "America/Montreal", WorkingHours: []string{"09:00-12:00"}}
+	people := []*types.Person{local, montreal}
+	assert.NoError(t, ResolveWorkingHours(people))
+	assert.Equal(t, "Europe/Paris", local.Timezone)
+	assert.Equal(t, []string{"09:00-12:00", "13:00-17:00"}, local.WorkingHours)
+	assert.Equal(t, []string{"09:00-12:00"}, montreal.WorkingHours)
+
+	ranges, err := GetPeopleWorkRanges(beginOfWeek, people)
+	assert.NoError(t, err)
+
+	// Each day has the Paris morning, and the Paris afternoon extended by the
+	// Montreal morning, from 14:00 to 17:00 Paris time
+	assert.Equal(t, 10, len(ranges))
+	assert.Equal(t, time.Date(2024, 3, 25, 9, 0, 0, 0, paris), ranges[0].Start)
+	assert.Equal(t, time.Date(2024, 3, 25, 13, 0, 0, 0, paris), ranges[1].Start)
+	assert.Equal(t, time.Date(2024, 3, 25, 17, 0, 0, 0, paris), ranges[1].End)
+
+	// Part-time people don't extend the work ranges on their days off
+	local.WorkingDays = []string{"monday", "tuesday"}
+	ranges, err = GetPeopleWorkRanges(beginOfWeek, people)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, len(ranges))
+	assert.Equal(t, time.Date(2024, 3, 27, 14, 0, 0, 0, paris), ranges[4].Start)
+	local.WorkingDays = nil
+
+	montreal.WorkingHours = []string{"10:00-16:00"}
+	ranges, err = GetPeopleWorkRanges(beginOfWeek, people)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 25, 21, 0, 0, 0, paris), ranges[1].End)
+}